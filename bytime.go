@@ -14,11 +14,11 @@ func (s byTime) Less(i, j int) bool {
 	// Two zero times should return false.
 	// Otherwise, zero is "greater" than any other time.
 	// (To sort it at the end of the list.)
-	if s[i].NextTime.IsZero() {
+	if s[i].RunTime.IsZero() {
 		return false
 	}
-	if s[j].NextTime.IsZero() {
+	if s[j].RunTime.IsZero() {
 		return true
 	}
-	return s[i].NextTime.Before(s[j].NextTime)
+	return s[i].RunTime.Before(s[j].RunTime)
 }
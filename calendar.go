@@ -0,0 +1,418 @@
+package cron
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Calendar decides which days are business days: every day except the
+// weekend and holidays, plus the make-up working days which fall on a
+// weekend (调休).
+//
+// A Calendar is not safe to modify while schedules using it are running.
+type Calendar struct {
+	// Weekend lists the days of the week which are not business days.
+	// NewCalendar sets it to Saturday and Sunday.
+	Weekend []time.Weekday
+
+	holidays map[int]bool
+	workdays map[int]bool
+}
+
+// NewCalendar returns a calendar with a Saturday and Sunday weekend and no
+// holidays.
+func NewCalendar() *Calendar {
+	return &Calendar{Weekend: []time.Weekday{time.Saturday, time.Sunday}}
+}
+
+// AddHolidays marks the dates of the given times, in their own locations, as
+// holidays.
+func (c *Calendar) AddHolidays(dates ...time.Time) {
+	if c.holidays == nil {
+		c.holidays = make(map[int]bool)
+	}
+	for _, d := range dates {
+		c.holidays[dateKey(d)] = true
+		delete(c.workdays, dateKey(d))
+	}
+}
+
+// AddWorkdays marks the dates of the given times, in their own locations, as
+// business days even if they fall on the weekend.
+func (c *Calendar) AddWorkdays(dates ...time.Time) {
+	if c.workdays == nil {
+		c.workdays = make(map[int]bool)
+	}
+	for _, d := range dates {
+		c.workdays[dateKey(d)] = true
+		delete(c.holidays, dateKey(d))
+	}
+}
+
+// IsHoliday reports whether the date of t was marked as a holiday.
+func (c *Calendar) IsHoliday(t time.Time) bool {
+	return c.holidays[dateKey(t)]
+}
+
+// IsBusinessDay reports whether the date of t, in its location, is a business
+// day.
+func (c *Calendar) IsBusinessDay(t time.Time) bool {
+	key := dateKey(t)
+	if c.workdays[key] {
+		return true
+	}
+	if c.holidays[key] {
+		return false
+	}
+	for _, w := range c.Weekend {
+		if t.Weekday() == w {
+			return false
+		}
+	}
+	return true
+}
+
+// LoadFile adds the holidays and workdays of an iCalendar file, if its name
+// ends with .ics, or of a date list otherwise.
+func (c *Calendar) LoadFile(name string) error {
+	f, err := os.Open(name)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	if strings.EqualFold(filepath.Ext(name), ".ics") {
+		err = c.LoadICS(f)
+	} else {
+		err = c.LoadDates(f)
+	}
+	if err != nil {
+		return fmt.Errorf("cron: failed to load calendar %s: %w", name, err)
+	}
+	return nil
+}
+
+// LoadDates adds the holidays and workdays of a date list. Each line holds a
+// holiday date, a holiday range, or a workday prefixed with a plus sign.
+// Blank lines and text after # are ignored:
+//
+//	2024-10-01/2024-10-07 # 国庆节
+//	+2024-09-29           # 调休上班
+//	+2024-10-12
+func (c *Calendar) LoadDates(r io.Reader) error {
+	scanner := bufio.NewScanner(r)
+	for n := 1; scanner.Scan(); n++ {
+		line := scanner.Text()
+		if i := strings.IndexByte(line, '#'); i >= 0 {
+			line = line[:i]
+		}
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		workday := strings.HasPrefix(line, "+")
+		if workday {
+			line = strings.TrimSpace(line[1:])
+		}
+		from, to := line, line
+		if i := strings.IndexByte(line, '/'); i >= 0 {
+			from, to = line[:i], line[i+1:]
+		}
+		start, err := time.Parse("2006-01-02", strings.TrimSpace(from))
+		if err != nil {
+			return fmt.Errorf("line %d: invalid date %q", n, from)
+		}
+		end, err := time.Parse("2006-01-02", strings.TrimSpace(to))
+		if err != nil {
+			return fmt.Errorf("line %d: invalid date %q", n, to)
+		}
+		if end.Before(start) {
+			return fmt.Errorf("line %d: range %s ends before it starts", n, line)
+		}
+		c.addRange(start, end.AddDate(0, 0, 1), workday)
+	}
+	return scanner.Err()
+}
+
+// LoadICS adds the events of an iCalendar file as holidays. Events whose
+// summary contains 补班 or 上班, or whose categories contain WORKDAY, are
+// make-up workdays instead, as in the usual Chinese holiday calendars. Events
+// last from DTSTART to DTEND, excluded, or for the single day of DTSTART.
+func (c *Calendar) LoadICS(r io.Reader) error {
+	var (
+		lines   []string
+		scanner = bufio.NewScanner(r)
+	)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		// Lines starting with a space or tab continue the previous one.
+		if len(lines) > 0 && (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")) {
+			lines[len(lines)-1] += line[1:]
+			continue
+		}
+		lines = append(lines, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+
+	var (
+		inEvent    bool
+		start, end time.Time
+		workday    bool
+	)
+	for n, line := range lines {
+		i := strings.IndexByte(line, ':')
+		if i < 0 {
+			continue
+		}
+		name, value := strings.ToUpper(line[:i]), strings.TrimSpace(line[i+1:])
+		if j := strings.IndexByte(name, ';'); j >= 0 {
+			name = name[:j]
+		}
+		switch {
+		case name == "BEGIN" && strings.EqualFold(value, "VEVENT"):
+			inEvent, start, end, workday = true, time.Time{}, time.Time{}, false
+		case !inEvent:
+		case name == "DTSTART", name == "DTEND":
+			d, err := icsDate(value)
+			if err != nil {
+				return fmt.Errorf("line %d: invalid %s %q", n+1, name, value)
+			}
+			if name == "DTSTART" {
+				start = d
+			} else {
+				end = d
+			}
+		case name == "SUMMARY":
+			workday = workday || strings.Contains(value, "补班") || strings.Contains(value, "上班")
+		case name == "CATEGORIES":
+			for _, category := range strings.Split(value, ",") {
+				workday = workday || strings.EqualFold(strings.TrimSpace(category), "WORKDAY")
+			}
+		case name == "END" && strings.EqualFold(value, "VEVENT"):
+			inEvent = false
+			if start.IsZero() {
+				return fmt.Errorf("line %d: event without DTSTART", n+1)
+			}
+			if !end.After(start) {
+				end = start.AddDate(0, 0, 1)
+			}
+			c.addRange(start, end, workday)
+		}
+	}
+	return nil
+}
+
+// addRange marks the dates from start to end, excluded.
+func (c *Calendar) addRange(start, end time.Time, workday bool) {
+	for d := start; d.Before(end); d = d.AddDate(0, 0, 1) {
+		if workday {
+			c.AddWorkdays(d)
+		} else {
+			c.AddHolidays(d)
+		}
+	}
+}
+
+// icsDate returns the date of an iCalendar DATE or DATE-TIME value.
+func icsDate(value string) (time.Time, error) {
+	if len(value) > 8 {
+		value = value[:8]
+	}
+	return time.Parse("20060102", value)
+}
+
+// dateKey identifies the date of t in its location.
+func dateKey(t time.Time) int {
+	y, m, d := t.Date()
+	return y*10000 + int(m)*100 + d
+}
+
+// RollPolicy decides what a CalendarSchedule does with activations which do
+// not fall on a business day.
+type RollPolicy int
+
+const (
+	// RollSkip drops them.
+	RollSkip RollPolicy = iota
+
+	// RollForward moves them to the same time on the next business day.
+	RollForward
+
+	// RollBackward moves them to the same time on the previous business
+	// day.
+	RollBackward
+)
+
+// maxRoll is how many days a calendar may go without a business day.
+const maxRoll = 366
+
+// CalendarSchedule restricts the activations of a schedule to the business
+// days of a calendar.
+type CalendarSchedule struct {
+	Schedule Schedule
+	Calendar *Calendar
+	Policy   RollPolicy
+
+	// Bound limits how far ahead Next looks. Zero means DefaultSearchBound.
+	Bound time.Duration
+}
+
+// OnBusinessDays returns a schedule activating whenever the given one does on
+// a business day of the calendar.
+func OnBusinessDays(schedule Schedule, calendar *Calendar) *CalendarSchedule {
+	return &CalendarSchedule{Schedule: schedule, Calendar: calendar}
+}
+
+// NextBusinessDay returns a schedule moving the activations of the given one
+// which are not on a business day to the next business day.
+func NextBusinessDay(schedule Schedule, calendar *Calendar) *CalendarSchedule {
+	return &CalendarSchedule{Schedule: schedule, Calendar: calendar, Policy: RollForward}
+}
+
+// PrevBusinessDay returns a schedule moving the activations of the given one
+// which are not on a business day to the previous business day, e.g. to pay
+// salaries before a holiday.
+func PrevBusinessDay(schedule Schedule, calendar *Calendar) *CalendarSchedule {
+	return &CalendarSchedule{Schedule: schedule, Calendar: calendar, Policy: RollBackward}
+}
+
+// Next returns the next activation on a business day, or the zero time if
+// there is none within the bound.
+func (s *CalendarSchedule) Next(t time.Time) time.Time {
+	loc := t.Location()
+	t = inScheduleLocation(s.Schedule, t)
+	limit := t.Add(bound(s.Bound))
+	from := t
+	if s.Policy == RollForward {
+		// Activations since the last business day may be moved past t.
+		day := t
+		for i := 0; i < maxRoll; i++ {
+			day = day.AddDate(0, 0, -1)
+			if s.Calendar.IsBusinessDay(day) {
+				break
+			}
+		}
+		from = midnight(day.AddDate(0, 0, 1)).Add(-time.Nanosecond)
+	}
+
+	next := s.Schedule.Next(from)
+	for i := 0; i < maxCandidates && !next.IsZero() && !next.After(limit); i++ {
+		if rolled, ok := s.roll(next); ok && rolled.After(t) {
+			return rolled.In(loc)
+		}
+		next = s.Schedule.Next(next)
+	}
+	return time.Time{}
+}
+
+// roll returns where the policy moves the activation t, if anywhere.
+func (s *CalendarSchedule) roll(t time.Time) (time.Time, bool) {
+	step := 0
+	switch s.Policy {
+	case RollForward:
+		step = 1
+	case RollBackward:
+		step = -1
+	}
+	for i := 0; i <= maxRoll; i++ {
+		day := t.AddDate(0, 0, i*step)
+		if s.Calendar.IsBusinessDay(day) {
+			return day, true
+		}
+		if step == 0 {
+			break
+		}
+	}
+	return time.Time{}, false
+}
+
+// NthBusinessDaySchedule restricts the activations of a schedule to the Nth
+// business day of each month.
+type NthBusinessDaySchedule struct {
+	Schedule Schedule
+	Calendar *Calendar
+
+	// N counts business days from the start of the month, starting at 1, or
+	// from its end if negative, -1 being the last business day.
+	N int
+
+	// Bound limits how far ahead Next looks. Zero means DefaultSearchBound.
+	Bound time.Duration
+}
+
+// NthBusinessDay returns a schedule activating whenever the given one does on
+// the Nth business day of the month, e.g. at 09:00 on the third business day
+// of every month:
+//
+//	daily, _ := cron.Parse("0 0 9 * * ?")
+//	payroll := cron.NthBusinessDay(daily, calendar, 3)
+func NthBusinessDay(schedule Schedule, calendar *Calendar, n int) *NthBusinessDaySchedule {
+	return &NthBusinessDaySchedule{Schedule: schedule, Calendar: calendar, N: n}
+}
+
+// Next returns the next activation on the Nth business day of a month, or the
+// zero time if there is none within the bound.
+func (s *NthBusinessDaySchedule) Next(t time.Time) time.Time {
+	if s.N == 0 {
+		return time.Time{}
+	}
+	loc := t.Location()
+	t = inScheduleLocation(s.Schedule, t)
+	limit := t.Add(bound(s.Bound))
+	month := time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, t.Location())
+	for ; !month.After(limit); month = month.AddDate(0, 1, 0) {
+		day, ok := s.day(month)
+		if !ok || day.AddDate(0, 0, 1).Before(t) {
+			continue
+		}
+		from := day.Add(-time.Nanosecond)
+		if t.After(from) {
+			from = t
+		}
+		next := s.Schedule.Next(from)
+		if next.IsZero() {
+			return time.Time{}
+		}
+		if dateKey(next.In(day.Location())) == dateKey(day) && !next.After(limit) {
+			return next.In(loc)
+		}
+	}
+	return time.Time{}
+}
+
+// day returns the midnight starting the Nth business day of the month.
+func (s *NthBusinessDaySchedule) day(month time.Time) (time.Time, bool) {
+	day, step, n := month, 1, s.N
+	if n < 0 {
+		day, step, n = month.AddDate(0, 1, -1), -1, -n
+	}
+	for ; day.Month() == month.Month(); day = day.AddDate(0, 0, step) {
+		if s.Calendar.IsBusinessDay(day) {
+			if n--; n == 0 {
+				return day, true
+			}
+		}
+	}
+	return time.Time{}, false
+}
+
+// midnight returns the start of the day of t.
+func midnight(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
+
+// inScheduleLocation returns t in the location of the schedule, if it has one,
+// so that dates match those of its activations. Like SpecSchedule.Next, the
+// schedules return their activations in the location of the time given.
+func inScheduleLocation(schedule Schedule, t time.Time) time.Time {
+	if spec, ok := schedule.(*SpecSchedule); ok && spec.Location != nil {
+		return t.In(spec.Location)
+	}
+	return t
+}
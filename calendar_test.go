@@ -0,0 +1,217 @@
+package cron
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// chinaHolidays2024 lists the National Day and Mid-Autumn holidays of 2024
+// with their make-up working days.
+const chinaHolidays2024 = `
+# 中秋节
+2024-09-15/2024-09-17
++2024-09-14
+
+# 国庆节
+2024-10-01/2024-10-07
++2024-09-29
++2024-10-12
+`
+
+func mustCalendar(t *testing.T) *Calendar {
+	cal := NewCalendar()
+	if err := cal.LoadDates(strings.NewReader(chinaHolidays2024)); err != nil {
+		t.Fatal(err)
+	}
+	return cal
+}
+
+func TestCalendar(t *testing.T) {
+	cal := mustCalendar(t)
+	tests := []struct {
+		time     string
+		business bool
+	}{
+		{"Fri Sep 13 09:00 2024", true},
+		{"Sat Sep 14 09:00 2024", true},
+		{"Sun Sep 15 09:00 2024", false},
+		{"Tue Sep 17 09:00 2024", false},
+		{"Wed Sep 18 09:00 2024", true},
+		{"Sat Sep 28 09:00 2024", false},
+		{"Sun Sep 29 09:00 2024", true},
+		{"Tue Oct 1 09:00 2024", false},
+		{"Mon Oct 7 23:59 2024", false},
+		{"Tue Oct 8 00:00 2024", true},
+		{"Sat Oct 12 09:00 2024", true},
+		{"Sun Oct 13 09:00 2024", false},
+	}
+	for _, c := range tests {
+		if actual := cal.IsBusinessDay(getTime(c.time)); actual != c.business {
+			t.Errorf("%s: expected business day %v, got %v", c.time, c.business, actual)
+		}
+	}
+
+	if !cal.IsHoliday(getTime("Tue Oct 1 09:00 2024")) || cal.IsHoliday(getTime("Sat Sep 28 09:00 2024")) {
+		t.Error("expected only marked dates to be holidays")
+	}
+
+	var zero Calendar
+	zero.AddHolidays(getTime("Tue Oct 1 09:00 2024"))
+	if zero.IsBusinessDay(getTime("Tue Oct 1 09:00 2024")) || !zero.IsBusinessDay(getTime("Sat Oct 12 09:00 2024")) {
+		t.Error("expected the zero calendar to have no weekend")
+	}
+}
+
+func TestCalendarSchedule(t *testing.T) {
+	cal := mustCalendar(t)
+	daily := mustParse(t, "0 0 9 * * ?")
+	monthly := mustParse(t, "0 0 9 1 * ?")
+
+	tests := []struct {
+		sched    Schedule
+		time     string
+		expected string
+	}{
+		{OnBusinessDays(daily, cal), "Fri Sep 27 10:00 2024", "Sun Sep 29 09:00 2024"},
+		{OnBusinessDays(daily, cal), "Mon Sep 30 10:00 2024", "Tue Oct 8 09:00 2024"},
+		{OnBusinessDays(daily, cal), "Tue Oct 8 08:00 2024", "Tue Oct 8 09:00 2024"},
+
+		{NextBusinessDay(monthly, cal), "Mon Sep 2 10:00 2024", "Tue Oct 8 09:00 2024"},
+		{NextBusinessDay(monthly, cal), "Thu Oct 3 10:00 2024", "Tue Oct 8 09:00 2024"},
+		{NextBusinessDay(monthly, cal), "Tue Oct 8 10:00 2024", "Fri Nov 1 09:00 2024"},
+		{NextBusinessDay(monthly, cal), "Sat Jun 1 10:00 2024", "Mon Jun 3 09:00 2024"},
+
+		{PrevBusinessDay(monthly, cal), "Mon Sep 2 10:00 2024", "Mon Sep 30 09:00 2024"},
+		{PrevBusinessDay(monthly, cal), "Mon Sep 30 10:00 2024", "Fri Nov 1 09:00 2024"},
+		{PrevBusinessDay(monthly, cal), "Fri Nov 1 10:00 2024", "Fri Nov 29 09:00 2024"},
+
+		{NthBusinessDay(daily, cal, 3), "Tue Oct 1 00:00 2024", "Thu Oct 10 09:00 2024"},
+		{NthBusinessDay(daily, cal, 3), "Thu Oct 10 09:00 2024", "Tue Nov 5 09:00 2024"},
+		{NthBusinessDay(daily, cal, 1), "Mon Sep 2 10:00 2024", "Tue Oct 8 09:00 2024"},
+		{NthBusinessDay(daily, cal, -1), "Sun Sep 1 00:00 2024", "Mon Sep 30 09:00 2024"},
+		{NthBusinessDay(daily, cal, -1), "Mon Sep 30 09:00 2024", "Thu Oct 31 09:00 2024"},
+		{NthBusinessDay(mustParse(t, "0 0 9 * * MON"), cal, 1), "Sun Sep 1 00:00 2024", "Mon Sep 2 09:00 2024"},
+		{NthBusinessDay(mustParse(t, "0 0 9 * * MON"), cal, 1), "Mon Sep 2 10:00 2024", "Mon Dec 2 09:00 2024"},
+		{NthBusinessDay(daily, cal, 30), "Sun Sep 1 00:00 2024", ""},
+		{NthBusinessDay(daily, cal, 0), "Sun Sep 1 00:00 2024", ""},
+
+		{OnBusinessDays(mustParse(t, "0 0 9 * * SAT,SUN"), NewCalendar()), "Sun Sep 1 00:00 2024", ""},
+	}
+	for i, c := range tests {
+		actual := c.sched.Next(getTime(c.time))
+		if !actual.Equal(getTime(c.expected)) {
+			t.Errorf("%d: %s: (expected) %v != %v (actual)", i, c.time, getTime(c.expected), actual)
+		}
+	}
+}
+
+func TestCalendarScheduleTz(t *testing.T) {
+	cal := mustCalendar(t)
+	sched := OnBusinessDays(mustParse(t, "CRON_TZ=Asia/Shanghai 0 0 9 * * ?"), cal)
+	// 20:00 UTC on Monday is already Tuesday, a holiday, in Shanghai.
+	actual := sched.Next(getTime("Mon Sep 30 20:00 2024"))
+	if !actual.Equal(getTime("Tue Oct 8 01:00 2024")) || actual.Location() != time.UTC {
+		t.Errorf("unexpected next %v", actual)
+	}
+
+	tests := []Schedule{
+		NextBusinessDay(mustParse(t, "CRON_TZ=Asia/Shanghai 0 0 9 1 * ?"), cal),
+		NthBusinessDay(mustParse(t, "CRON_TZ=Asia/Shanghai 0 0 9 * * ?"), cal, 1),
+	}
+	ny, _ := time.LoadLocation("America/New_York")
+	for i, sched := range tests {
+		actual := sched.Next(getTime("Mon Sep 30 20:00 2024").In(ny))
+		if !actual.Equal(getTime("Tue Oct 8 01:00 2024")) || actual.Location() != ny {
+			t.Errorf("%d: expected %v in the location given, got %v", i, getTime("Tue Oct 8 01:00 2024").In(ny), actual)
+		}
+	}
+}
+
+func TestCalendarLoadDates(t *testing.T) {
+	tests := []struct {
+		input string
+		err   string
+	}{
+		{"2024-10-01\n\n  # comment\n+2024-10-12 # workday", ""},
+		{"2024-10-1", `line 1: invalid date "2024-10-1"`},
+		{"2024-10-01\n2024-10-07/2024-10-01", "line 2: range 2024-10-07/2024-10-01 ends before it starts"},
+		{"2024-10-01/", `line 1: invalid date ""`},
+	}
+	for _, c := range tests {
+		err := NewCalendar().LoadDates(strings.NewReader(c.input))
+		if c.err == "" && err != nil || c.err != "" && (err == nil || err.Error() != c.err) {
+			t.Errorf("%q: expected error %q, got %v", c.input, c.err, err)
+		}
+	}
+}
+
+func TestCalendarLoadICS(t *testing.T) {
+	ics := strings.Join([]string{
+		"BEGIN:VCALENDAR",
+		"VERSION:2.0",
+		"BEGIN:VEVENT",
+		"DTSTART;VALUE=DATE:20241001",
+		"DTEND;VALUE=DATE:20241008",
+		"SUMMARY:国庆节",
+		"END:VEVENT",
+		"BEGIN:VEVENT",
+		"DTSTART;VALUE=DATE:20240929",
+		"SUMMARY:国庆节 ",
+		" 补班",
+		"END:VEVENT",
+		"BEGIN:VEVENT",
+		"DTSTART:20241012T000000Z",
+		"DTEND:20241012T235959Z",
+		"CATEGORIES:HOLIDAY,WORKDAY",
+		"END:VEVENT",
+		"END:VCALENDAR",
+	}, "\r\n")
+
+	cal := NewCalendar()
+	if err := cal.LoadICS(strings.NewReader(ics)); err != nil {
+		t.Fatal(err)
+	}
+	expected := NewCalendar()
+	if err := expected.LoadDates(strings.NewReader(chinaHolidays2024[strings.Index(chinaHolidays2024, "# 国庆节"):])); err != nil {
+		t.Fatal(err)
+	}
+	for d := getTime("Sun Sep 1 00:00 2024"); d.Month() != time.November; d = d.AddDate(0, 0, 1) {
+		if cal.IsBusinessDay(d) != expected.IsBusinessDay(d) {
+			t.Errorf("%v: expected business day %v", d, expected.IsBusinessDay(d))
+		}
+	}
+
+	err := NewCalendar().LoadICS(strings.NewReader("BEGIN:VEVENT\nDTSTART:2024\nEND:VEVENT"))
+	if err == nil || err.Error() != `line 2: invalid DTSTART "2024"` {
+		t.Errorf("unexpected error %v", err)
+	}
+	err = NewCalendar().LoadICS(strings.NewReader("BEGIN:VEVENT\nSUMMARY:x\nEND:VEVENT"))
+	if err == nil || err.Error() != "line 3: event without DTSTART" {
+		t.Errorf("unexpected error %v", err)
+	}
+}
+
+func TestCalendarLoadFile(t *testing.T) {
+	dir := t.TempDir()
+	dates := filepath.Join(dir, "holidays.txt")
+	ics := filepath.Join(dir, "holidays.ics")
+	os.WriteFile(dates, []byte("2024-10-01\n"), 0o644)
+	os.WriteFile(ics, []byte("BEGIN:VEVENT\nDTSTART;VALUE=DATE:20241002\nEND:VEVENT\n"), 0o644)
+
+	cal := NewCalendar()
+	if err := cal.LoadFile(dates); err != nil {
+		t.Fatal(err)
+	}
+	if err := cal.LoadFile(ics); err != nil {
+		t.Fatal(err)
+	}
+	if !cal.IsHoliday(getTime("Tue Oct 1 00:00 2024")) || !cal.IsHoliday(getTime("Wed Oct 2 00:00 2024")) {
+		t.Error("expected holidays from both files")
+	}
+	if err := cal.LoadFile(filepath.Join(dir, "missing.txt")); err == nil {
+		t.Error("expected an error for a missing file")
+	}
+}
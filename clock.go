@@ -0,0 +1,103 @@
+package cron
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Clock tells the time and makes timers. The Cron uses it to schedule entries,
+// to enforce job timeouts, to wait between retries and for a worker of its
+// Pool, so that tests may replace the wall clock with a FakeClock.
+type Clock interface {
+	// Now returns the current time.
+	Now() time.Time
+
+	// NewTimer returns a Timer sending the current time on its channel after
+	// at least duration d.
+	NewTimer(d time.Duration) Timer
+}
+
+// Timer is the Clock counterpart of time.Timer.
+type Timer interface {
+	// C returns the channel on which the time is delivered.
+	C() <-chan time.Time
+
+	// Stop prevents the Timer from firing. It returns false if the timer has
+	// already expired or been stopped.
+	Stop() bool
+}
+
+// WallClock is the Clock backed by package time.
+var WallClock Clock = wallClock{}
+
+type wallClock struct{}
+
+func (wallClock) Now() time.Time { return time.Now() }
+
+func (wallClock) NewTimer(d time.Duration) Timer { return wallTimer{time.NewTimer(d)} }
+
+type wallTimer struct {
+	*time.Timer
+}
+
+func (t wallTimer) C() <-chan time.Time { return t.Timer.C }
+
+// timeoutContext is a context cancelled when a timer of a Clock fires, whose
+// Err is then context.DeadlineExceeded.
+type timeoutContext struct {
+	context.Context
+	deadline time.Time
+
+	mux     sync.Mutex
+	expired bool
+	stopped bool
+}
+
+// withTimeout is context.WithTimeout on the time of clock.
+func withTimeout(parent context.Context, clock Clock, d time.Duration) (*timeoutContext, context.CancelFunc) {
+	inner, cancel := context.WithCancelCause(parent)
+	ctx := &timeoutContext{Context: inner, deadline: clock.Now().Add(d)}
+	timer := clock.NewTimer(d)
+	go func() {
+		select {
+		case <-timer.C():
+			ctx.mux.Lock()
+			if !ctx.stopped {
+				ctx.expired = inner.Err() == nil
+				cancel(context.DeadlineExceeded)
+			}
+			ctx.mux.Unlock()
+		case <-inner.Done():
+			timer.Stop()
+		}
+	}()
+	return ctx, func() { cancel(context.Canceled) }
+}
+
+// stop keeps the timer from expiring the context any more, and reports
+// whether it did so already: whether the deadline came first.
+func (c *timeoutContext) stop() bool {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	c.stopped = true
+	return c.expired
+}
+
+// Deadline returns the earlier of the deadlines of the clock and the parent.
+func (c *timeoutContext) Deadline() (time.Time, bool) {
+	if deadline, ok := c.Context.Deadline(); ok && deadline.Before(c.deadline) {
+		return deadline, true
+	}
+	return c.deadline, true
+}
+
+// Err returns context.DeadlineExceeded if the timer fired first.
+func (c *timeoutContext) Err() error {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	if c.expired {
+		return context.DeadlineExceeded
+	}
+	return c.Context.Err()
+}
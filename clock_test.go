@@ -0,0 +1,103 @@
+package cron
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestFakeClockTimers(t *testing.T) {
+	start := time.Date(2012, time.July, 9, 14, 45, 0, 0, time.UTC)
+	clock := NewFakeClock(start)
+
+	early := clock.NewTimer(time.Second)
+	late := clock.NewTimer(time.Minute)
+	stopped := clock.NewTimer(time.Second)
+	if !stopped.Stop() {
+		t.Error("expected pending timer stops")
+	}
+
+	clock.Advance(30 * time.Second)
+	select {
+	case now := <-early.C():
+		if !now.Equal(start.Add(30 * time.Second)) {
+			t.Errorf("expected timer sends clock time, got %v", now)
+		}
+	default:
+		t.Error("expected timer due fires")
+	}
+	select {
+	case <-late.C():
+		t.Error("expected timer not due does not fire")
+	case <-stopped.C():
+		t.Error("expected stopped timer does not fire")
+	default:
+	}
+
+	clock.Set(start.Add(time.Hour))
+	select {
+	case <-late.C():
+	default:
+		t.Error("expected timer due fires")
+	}
+	if early.Stop() {
+		t.Error("expected fired timer does not stop")
+	}
+}
+
+// Test that a timeout context reports whether its deadline passed before it
+// was stopped, and no longer expires once stopped.
+func TestTimeoutContextStop(t *testing.T) {
+	clock := NewFakeClock(time.Date(2012, time.July, 9, 14, 45, 0, 0, time.UTC))
+
+	returned, cancel := withTimeout(context.Background(), clock, time.Second)
+	defer cancel()
+	clock.BlockUntil(1)
+	if returned.stop() {
+		t.Error("expected the job to return first")
+	}
+	clock.Advance(time.Second)
+	if err := returned.Err(); err != nil {
+		t.Errorf("expected no deadline once stopped, got %v", err)
+	}
+
+	late, cancel := withTimeout(context.Background(), clock, time.Second)
+	defer cancel()
+	clock.BlockUntil(1)
+	clock.Advance(time.Second)
+	<-late.Done()
+	if !late.stop() || late.Err() != context.DeadlineExceeded {
+		t.Errorf("expected the deadline first, got %v", late.Err())
+	}
+}
+
+// Test that entries run as the fake clock is advanced, without waiting on the
+// wall clock.
+func TestCronWithFakeClock(t *testing.T) {
+	clock := NewFakeClock(time.Date(2012, time.July, 9, 14, 45, 0, 0, time.UTC))
+	runs := make(chan time.Time, 10)
+
+	cron := NewWithClock(time.UTC, clock)
+	cron.AddFunc2("0 0 * * * ?", func(ctx *JobContext) { runs <- clock.Now() }, "hourly")
+	cron.Start()
+	defer cron.Stop()
+
+	for _, expected := range []string{"Mon Jul 9 15:00 2012", "Mon Jul 9 16:00 2012"} {
+		clock.BlockUntil(1)
+		clock.Set(getTime(expected))
+
+		select {
+		case <-time.After(OneSecond):
+			t.Fatalf("expected job runs at %s", expected)
+		case actual := <-runs:
+			if !actual.Equal(getTime(expected)) {
+				t.Errorf("(expected) %v != %v (actual)", getTime(expected), actual)
+			}
+		}
+	}
+
+	entry := cron.Entries()[0]
+	if entry.Count != 2 || !entry.NextTime.Equal(getTime("Mon Jul 9 17:00 2012")) {
+		t.Errorf("unexpected entry count %d, next %v", entry.Count, entry.NextTime)
+	}
+}
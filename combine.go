@@ -0,0 +1,185 @@
+package cron
+
+import "time"
+
+// DefaultSearchBound is how far ahead Intersect and Except look for an
+// activation when their Bound is zero, matching the five years of
+// SpecSchedule.
+const DefaultSearchBound = 5 * 366 * 24 * time.Hour
+
+// maxCandidates caps the activations of their schedules that Intersect and
+// Except try in a single call to Next.
+const maxCandidates = 1 << 20
+
+// UnionSchedule activates whenever any of its schedules does.
+type UnionSchedule []Schedule
+
+// Union returns a schedule activating whenever any of the given ones does.
+func Union(schedules ...Schedule) UnionSchedule {
+	return UnionSchedule(schedules)
+}
+
+// Next returns the earliest next activation of the schedules.
+func (u UnionSchedule) Next(t time.Time) time.Time {
+	var next time.Time
+	for _, s := range u {
+		if n := s.Next(t); !n.IsZero() && (next.IsZero() || n.Before(next)) {
+			next = n
+		}
+	}
+	return next
+}
+
+// IntersectSchedule activates whenever all of its schedules do at the same
+// time.
+type IntersectSchedule struct {
+	Schedules []Schedule
+
+	// Bound limits how far ahead Next looks. Zero means DefaultSearchBound.
+	Bound time.Duration
+}
+
+// Intersect returns a schedule activating whenever all of the given ones do,
+// e.g. the Mondays which are also the 1st of the month. A
+// ConstantDelaySchedule among them activates at the multiples of its Delay
+// since the Unix epoch, e.g. every hour on the hour.
+func Intersect(schedules ...Schedule) *IntersectSchedule {
+	return &IntersectSchedule{Schedules: schedules}
+}
+
+// Next returns the next time all schedules activate, or the zero time if
+// there is none within the bound.
+func (in *IntersectSchedule) Next(t time.Time) time.Time {
+	if len(in.Schedules) == 0 {
+		return time.Time{}
+	}
+	var (
+		limit = t.Add(bound(in.Bound))
+		next  = atOrAfter(in.Schedules[0], t.Add(time.Nanosecond))
+	)
+	for i := 0; i < maxCandidates && !next.IsZero() && !next.After(limit); i++ {
+		// Move to the latest of the first activations from next on; next
+		// is the answer once all schedules agree on it.
+		agreed := true
+		for _, s := range in.Schedules {
+			n := atOrAfter(s, next)
+			if n.IsZero() {
+				return time.Time{}
+			}
+			if !n.Equal(next) {
+				agreed = false
+				if n.After(next) {
+					next = n
+				}
+			}
+		}
+		if agreed {
+			return next
+		}
+	}
+	return time.Time{}
+}
+
+// ExceptSchedule activates whenever its Schedule does, but its Excluded
+// schedule does not.
+type ExceptSchedule struct {
+	Schedule Schedule
+	Excluded Schedule
+
+	// Bound limits how far ahead Next looks. Zero means DefaultSearchBound.
+	Bound time.Duration
+}
+
+// Except returns a schedule activating whenever the given one does, except
+// at the activations of excluded. An excluded ConstantDelaySchedule activates
+// at the multiples of its Delay since the Unix epoch. A SpecSchedule
+// activating every second of a window excludes the window, e.g.:
+//
+//	every15, _ := cron.Parse("0 */15 * * * ?")
+//	maintenance, _ := cron.Parse("* * 2 * * ?")
+//	sched := cron.Except(every15, maintenance)
+func Except(schedule, excluded Schedule) *ExceptSchedule {
+	return &ExceptSchedule{Schedule: schedule, Excluded: excluded}
+}
+
+// Next returns the next activation of the schedule which is not excluded, or
+// the zero time if there is none within the bound.
+func (e *ExceptSchedule) Next(t time.Time) time.Time {
+	limit := t.Add(bound(e.Bound))
+	next := e.Schedule.Next(t)
+	for i := 0; i < maxCandidates && !next.IsZero() && !next.After(limit); i++ {
+		if !activates(e.Excluded, next) {
+			return next
+		}
+		next = e.Schedule.Next(next)
+	}
+	return time.Time{}
+}
+
+// BetweenSchedule activates every second from Start, included, to End,
+// excluded. A zero Start or End leaves that side open.
+type BetweenSchedule struct {
+	Start, End time.Time
+}
+
+// Between returns a schedule activating every second of a time window. It
+// restricts other schedules to the window with Intersect, or excludes the
+// window from them with Except:
+//
+//	cron.Intersect(sched, cron.Between(launch, launch.AddDate(0, 1, 0)))
+func Between(start, end time.Time) BetweenSchedule {
+	return BetweenSchedule{Start: start, End: end}
+}
+
+// Next returns the next second within the window, or the zero time if the
+// window has passed.
+func (b BetweenSchedule) Next(t time.Time) time.Time {
+	next := t.Truncate(time.Second).Add(time.Second)
+	if next.Before(b.Start) {
+		next = b.Start
+	}
+	if !b.End.IsZero() && !next.Before(b.End) {
+		return time.Time{}
+	}
+	return next
+}
+
+// activates reports whether the schedule activates at t.
+func activates(s Schedule, t time.Time) bool {
+	return atOrAfter(s, t).Equal(t)
+}
+
+// atOrAfter returns the first activation of the schedule at or after t. The
+// activations of a ConstantDelaySchedule depend on the time it is asked about,
+// so it is aligned on the multiples of its Delay since the Unix epoch instead.
+func atOrAfter(s Schedule, t time.Time) time.Time {
+	switch s := s.(type) {
+	case ConstantDelaySchedule:
+		return aligned(s.Delay, t)
+	case *ConstantDelaySchedule:
+		return aligned(s.Delay, t)
+	}
+	return s.Next(t.Add(-time.Nanosecond))
+}
+
+// aligned returns the first multiple of delay since the Unix epoch at or
+// after t.
+func aligned(delay time.Duration, t time.Time) time.Time {
+	if delay < time.Second {
+		delay = time.Second
+	}
+	if r := time.Duration(t.UnixNano() % int64(delay)); r > 0 {
+		return t.Add(delay - r)
+	} else if r < 0 {
+		return t.Add(-r)
+	}
+	return t
+}
+
+// bound returns the search bound d, or the default one if d is zero.
+func bound(d time.Duration) time.Duration {
+	if d <= 0 {
+		return DefaultSearchBound
+	}
+	return d
+}
@@ -0,0 +1,94 @@
+package cron
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCombinators(t *testing.T) {
+	every15 := mustParse(t, "0 */15 * * * ?")
+	mondays := mustParse(t, "0 0 9 * * MON")
+	firsts := mustParse(t, "0 0 9 1 * ?")
+
+	tests := []struct {
+		sched    Schedule
+		time     string
+		expected string
+	}{
+		// Every 15 minutes, except during the 02:00-03:00 maintenance window.
+		{Except(every15, mustParse(t, "* * 2 * * ?")), "Mon Jul 9 01:40 2012", "Mon Jul 9 01:45 2012"},
+		{Except(every15, mustParse(t, "* * 2 * * ?")), "Mon Jul 9 01:45 2012", "Mon Jul 9 03:00 2012"},
+		{Except(every15, mustParse(t, "* * 2 * * ?")), "Mon Jul 9 02:20 2012", "Mon Jul 9 03:00 2012"},
+
+		// Mondays which are the 1st of the month.
+		{Intersect(mondays, firsts), "Mon Jul 9 00:00 2012", "Mon Oct 1 09:00 2012"},
+		{Intersect(firsts, mondays), "Mon Jul 9 00:00 2012", "Mon Oct 1 09:00 2012"},
+		{Intersect(every15, Every(time.Second)), "Mon Jul 9 00:00 2012", "Mon Jul 9 00:15 2012"},
+		{Intersect(every15, Every(time.Minute)), "Mon Jul 9 00:00 2012", "Mon Jul 9 00:15 2012"},
+		{Intersect(every15, Every(time.Hour)), "Mon Jul 9 00:00 2012", "Mon Jul 9 01:00 2012"},
+		{Intersect(Every(10*time.Minute), mustParse(t, "0 */7 * * * ?")), "Mon Jul 9 00:00 2012", "Mon Jul 9 01:00 2012"},
+		{Intersect(every15, Every(time.Minute+time.Second)), "Mon Jul 9 00:00 2012", "Mon Jul 9 05:15 2012"},
+		{Except(every15, Every(time.Hour)), "Mon Jul 9 00:50 2012", "Mon Jul 9 01:15 2012"},
+		{Except(every15, &ConstantDelaySchedule{Delay: 30 * time.Minute}), "Mon Jul 9 00:10 2012", "Mon Jul 9 00:15 2012"},
+		{Except(every15, &ConstantDelaySchedule{Delay: 30 * time.Minute}), "Mon Jul 9 00:15 2012", "Mon Jul 9 00:45 2012"},
+
+		// Earliest of the schedules.
+		{Union(mondays, firsts), "Mon Jul 9 10:00 2012", "Mon Jul 16 09:00 2012"},
+		{Union(mondays, firsts), "Mon Jul 30 10:00 2012", "Wed Aug 1 09:00 2012"},
+		{Union(mondays, Every(time.Hour)), "Mon Jul 9 10:00 2012", "Mon Jul 9 11:00 2012"},
+		{Union(&ZeroSchedule{}, firsts), "Mon Jul 9 10:00 2012", "Wed Aug 1 09:00 2012"},
+
+		// Windows.
+		{Intersect(every15, Between(getTime("Mon Jul 9 10:20 2012"), getTime("Mon Jul 9 11:00 2012"))), "Mon Jul 9 00:00 2012", "Mon Jul 9 10:30 2012"},
+		{Intersect(every15, Between(getTime("Mon Jul 9 10:20 2012"), getTime("Mon Jul 9 11:00 2012"))), "Mon Jul 9 10:45 2012", ""},
+		{Intersect(every15, Between(time.Time{}, getTime("Mon Jul 9 11:00 2012"))), "Mon Jul 9 10:35 2012", "Mon Jul 9 10:45 2012"},
+		{Except(every15, Between(getTime("Mon Jul 9 10:20 2012"), getTime("Mon Jul 9 11:00 2012"))), "Mon Jul 9 10:10 2012", "Mon Jul 9 10:15 2012"},
+		{Except(every15, Between(getTime("Mon Jul 9 10:20 2012"), getTime("Mon Jul 9 11:00 2012"))), "Mon Jul 9 10:15 2012", "Mon Jul 9 11:00 2012"},
+		{Between(getTime("Mon Jul 9 10:20 2012"), time.Time{}), "Mon Jul 9 10:35:10 2012", "Mon Jul 9 10:35:11 2012"},
+
+		// Unsatisfiable.
+		{Intersect(mustParse(t, "0 0 0 30 2 ?"), every15), "Mon Jul 9 00:00 2012", ""},
+		{Intersect(mustParse(t, "0 0 * * * ?"), mustParse(t, "0 30 * * * ?")), "Mon Jul 9 00:00 2012", ""},
+		{Except(every15, mustParse(t, "* * * * * ?")), "Mon Jul 9 00:00 2012", ""},
+		{Intersect(), "Mon Jul 9 00:00 2012", ""},
+		{Union(), "Mon Jul 9 00:00 2012", ""},
+	}
+
+	for i, c := range tests {
+		actual := c.sched.Next(getTime(c.time))
+		if !actual.Equal(getTime(c.expected)) {
+			t.Errorf("%d: %s: (expected) %v != %v (actual)", i, c.time, getTime(c.expected), actual)
+		}
+	}
+}
+
+func TestCombinatorBound(t *testing.T) {
+	leap := mustParse(t, "0 0 0 29 2 ?")
+	sched := Intersect(leap, mustParse(t, "0 0 0 * * MON"))
+	if actual := sched.Next(getTime("Mon Jul 9 00:00 2012")); !actual.Equal(getTime("Mon Feb 29 00:00 2016")) {
+		t.Errorf("unexpected next %v", actual)
+	}
+	sched.Bound = 365 * 24 * time.Hour
+	if actual := sched.Next(getTime("Mon Jul 9 00:00 2012")); !actual.IsZero() {
+		t.Errorf("expected no activation within the bound, got %v", actual)
+	}
+
+	except := Except(leap, mustParse(t, "0 0 0 * * MON"))
+	if actual := except.Next(getTime("Thu Jul 9 00:00 2015")); !actual.Equal(getTime("Sat Feb 29 00:00 2020")) {
+		t.Errorf("unexpected next %v", actual)
+	}
+	except.Bound = 2 * 365 * 24 * time.Hour
+	if actual := except.Next(getTime("Thu Jul 9 00:00 2015")); !actual.IsZero() {
+		t.Errorf("expected no activation within the bound, got %v", actual)
+	}
+}
+
+func TestScheduleCombinator(t *testing.T) {
+	cron := New()
+	sched := Except(mustParse(t, "0 */15 * * * ?"), mustParse(t, "* * 2 * * ?"))
+	cron.Schedule2(sched, Job2Wrapper(func(*JobContext) {}), "combined")
+	entries := cron.Entries()
+	if len(entries) != 1 || entries[0].Schedule != Schedule(sched) {
+		t.Fatalf("unexpected entries %v", entries)
+	}
+}
@@ -1,11 +1,15 @@
 package cron
 
 import (
+	"context"
 	"fmt"
 	"log"
+	"math/rand"
 	"runtime"
 	"sort"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
@@ -20,8 +24,20 @@ type Cron struct {
 	remove      chan string
 	running     bool
 	ErrorLogger *log.Logger
+	Pool        *Pool
+	Store       Store
+	Locker      Locker
 	location    *time.Location
+	clock       Clock
 	mux         *sync.RWMutex
+	ctx         context.Context
+	cancel      context.CancelFunc
+	jobs        *sync.WaitGroup
+	runMux      *sync.Mutex
+	runs        map[string]int
+	drain       chan struct{}
+	stored      map[string]EntryState
+	names       uint64
 }
 
 // JobEntry consists of a schedule and the func to execute on that schedule.
@@ -37,6 +53,17 @@ type JobEntry struct {
 	// been run.
 	PrevTime time.Time
 
+	// The time the next run is dispatched: NextTime delayed by Offset and a
+	// random part of Jitter. It is NextTime if neither is set.
+	RunTime time.Time
+
+	// Offset delays every run by a fixed duration after its NextTime.
+	Offset time.Duration
+
+	// Jitter delays every run by a random duration in [0, Jitter), drawn
+	// anew for each run, to spread the load of identical schedules.
+	Jitter time.Duration
+
 	// The Job to run.
 	Job Job2
 
@@ -45,6 +72,80 @@ type JobEntry struct {
 
 	// Count of runs
 	Count int
+
+	// NotBefore and NotAfter bound the activations of the schedule which
+	// run the job. A zero time leaves that side open.
+	NotBefore time.Time
+	NotAfter  time.Time
+
+	// MaxRuns retires the entry once Count reaches it. Zero means no limit.
+	MaxRuns int
+
+	// Completed reports, in the entries returned by Entries, that the entry
+	// was retired after its MaxRuns or NotAfter. It has no NextTime and stays
+	// listed until removed.
+	Completed bool
+
+	// Timeout bounds a single run of the job. The context passed to the job
+	// is cancelled once it expires. Zero means no timeout.
+	Timeout time.Duration
+
+	// TimeoutGrace, if positive, is how long a run may outlive its Timeout
+	// before it is abandoned: the scheduler stops waiting for it, so that
+	// queued runs and the Pool proceed, while its goroutine is left behind.
+	TimeoutGrace time.Duration
+
+	// Overlap decides what happens when the job is due while a previous run
+	// is still active. The default allows concurrent runs.
+	Overlap OverlapPolicy
+
+	// MaxQueued bounds the number of runs waiting with OverlapQueue.
+	// Zero means 1.
+	MaxQueued int
+
+	// Misfire decides what happens to the runs missed while the Cron was not
+	// running (since PrevTime), or fired later than MisfireThreshold.
+	Misfire MisfirePolicy
+
+	// MisfireThreshold is how late a run may fire and still count as on
+	// time. Zero treats every run fired by the timer as on time.
+	MisfireThreshold time.Duration
+
+	// MaxMisfires caps the runs fired by MisfireFireAll. Zero means
+	// DefaultMaxMisfires.
+	MaxMisfires int
+
+	// Group names the Pool group limiting the concurrent runs of the job.
+	Group string
+
+	// Retry decides whether and when a failed run is attempted again.
+	// Only Job3 jobs and panics report failures. Nil means no retries.
+	Retry *RetryPolicy
+
+	// The outcome of the last finished run, and its error if it failed.
+	LastOutcome Outcome
+	LastError   error
+
+	state *entryState
+
+	// done marks the entry retired.
+	done bool
+
+	// once marks an entry added by AddOnceFunc, dropped once retired.
+	once bool
+
+	// align schedules a ConstantDelaySchedule on multiples of its delay
+	// since the Unix epoch, so that replicas sharing a Locker agree on its
+	// occurrences.
+	align bool
+
+	// generated marks a Name made up by the Cron, not kept by the Store.
+	generated bool
+
+	// ctx is the parent context of every run of this entry, cancelled when
+	// the entry is removed or the Cron is stopped.
+	ctx    context.Context
+	cancel context.CancelFunc
 }
 
 // New returns a new Cron job runner, in the Local time zone.
@@ -59,6 +160,11 @@ func NewDefault() *Cron {
 
 // NewWithLocation returns a new Cron job runner.
 func NewWithLocation(location *time.Location) *Cron {
+	return NewWithClock(location, WallClock)
+}
+
+// NewWithClock returns a new Cron job runner, telling the time with clock.
+func NewWithClock(location *time.Location, clock Clock) *Cron {
 	return &Cron{
 		entries:     nil,
 		add:         make(chan *JobEntry, 1),
@@ -68,7 +174,11 @@ func NewWithLocation(location *time.Location) *Cron {
 		running:     false,
 		ErrorLogger: nil,
 		location:    location,
+		clock:       clock,
 		mux:         new(sync.RWMutex),
+		jobs:        new(sync.WaitGroup),
+		runMux:      new(sync.Mutex),
+		runs:        make(map[string]int),
 	}
 }
 
@@ -81,15 +191,31 @@ func (c *Cron) AddJob(spec string, job Job, names ...string) error {
 // AddJob adds a Job2 to the Cron to be run on the given schedule and name.
 // Return error if failed to parse spec, otherwise nil
 func (c *Cron) AddJob2(spec string, job Job2, names ...string) error {
-	schedule, err := Parse(spec)
+	name, err := c.entryName(names)
 	if err != nil {
 		return err
 	}
-	c.Schedule2(schedule, job, c.makeName(names))
+	schedule, err := ParseWithName(spec, name)
+	if err != nil {
+		return err
+	}
+	c.AddEntry(&JobEntry{
+		Schedule:  schedule,
+		Job:       job,
+		Name:      name,
+		generated: len(names) == 0,
+	})
 	return nil
 }
 
-// Remove an entry from being run in the future.
+// AddJob3 adds a Job3 to the Cron to be run on the given schedule and name.
+// Return error if failed to parse spec, otherwise nil
+func (c *Cron) AddJob3(spec string, job Job3, names ...string) error {
+	return c.AddJob2(spec, NewJob3To2(job), names...)
+}
+
+// Remove an entry from being run in the future. The context of any of its
+// runs still in progress is cancelled.
 func (c *Cron) Remove(name string) {
 	c.mux.Lock()
 	defer c.mux.Unlock()
@@ -100,6 +226,8 @@ func (c *Cron) Remove(name string) {
 			return
 		}
 		c.entries = c.entries[:idx+copy(c.entries[idx:], c.entries[idx+1:])]
+		c.forget(name)
+		return
 	}
 	c.remove <- name
 }
@@ -111,14 +239,37 @@ func (c *Cron) Schedule(schedule Schedule, job Job, names ...string) {
 
 // Schedule adds a Job to the Cron to be run on the given schedule.
 func (c *Cron) Schedule2(schedule Schedule, job Job2, names ...string) {
-	c.mux.Lock()
-	defer c.mux.Unlock()
-
 	entry := &JobEntry{
 		Schedule: schedule,
 		Job:      job,
-		Name:     c.makeName(names),
 	}
+	if len(names) > 0 {
+		entry.Name = names[0]
+	}
+	c.AddEntry(entry)
+}
+
+// Schedule3 adds a Job3 to the Cron to be run on the given schedule.
+func (c *Cron) Schedule3(schedule Schedule, job Job3, names ...string) {
+	c.Schedule2(schedule, NewJob3To2(job), names...)
+}
+
+// AddEntry adds a fully configured entry to the Cron. Schedule and Job must
+// be set; options such as Timeout are taken from the entry. A name is
+// generated if entry.Name is empty; generated names change on every start,
+// so the state of such entries is not kept by the Store.
+func (c *Cron) AddEntry(entry *JobEntry) {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+
+	if entry.Name == "" {
+		entry.Name = c.makeName(nil)
+		entry.generated = true
+	}
+	if entry.generated && c.Store != nil {
+		c.logf("cron: %s has no name, its state is not stored", entry.Name)
+	}
+	entry.state = new(entryState)
 
 	if !c.running {
 		p := c.indexByName(entry.Name)
@@ -154,18 +305,91 @@ func (c *Cron) Start() {
 		return
 	}
 	c.running = true
+	c.ctx, c.cancel = context.WithCancel(context.Background())
+	c.runMux.Lock()
+	c.drain = make(chan struct{})
+	c.runMux.Unlock()
 	go c.scheduleJobs()
 }
 
 // Stop stops the cron scheduler if it is running; otherwise it does nothing.
+// Jobs already running are not waited for, but their contexts are cancelled.
 func (c *Cron) Stop() {
 	if !c.running {
 		return
 	}
+	c.halt()
+	c.cancel()
+}
+
+// Shutdown stops the cron scheduler and drops the runs queued by
+// OverlapQueue, then waits for the jobs still running to return. Unlike Stop, their contexts are left alone, so that they can
+// finish normally; they are cancelled only if ctx is done first. The returned
+// error then wraps ctx.Err() and names the jobs that were still running.
+func (c *Cron) Shutdown(ctx context.Context) error {
+	cancel := c.cancel
+	if c.running {
+		c.halt()
+	}
+	c.runMux.Lock()
+	if c.drain != nil {
+		select {
+		case <-c.drain:
+		default:
+			close(c.drain)
+		}
+	}
+	c.runMux.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		c.jobs.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		if cancel != nil {
+			cancel()
+		}
+		return nil
+	case <-ctx.Done():
+		running := c.Running()
+		if cancel != nil {
+			cancel()
+		}
+		return fmt.Errorf("cron: shutdown: %w, still running: %s",
+			ctx.Err(), strings.Join(running, ", "))
+	}
+}
+
+// halt stops the scheduler loop, so that no new runs start.
+func (c *Cron) halt() {
 	c.stop <- struct{}{}
 	c.running = false
 }
 
+// draining returns a channel closed once Shutdown begins, so that runs
+// waiting to start are dropped instead.
+func (c *Cron) draining() <-chan struct{} {
+	c.runMux.Lock()
+	defer c.runMux.Unlock()
+	return c.drain
+}
+
+// Running returns the names of the jobs currently running, sorted.
+func (c *Cron) Running() []string {
+	c.runMux.Lock()
+	defer c.runMux.Unlock()
+
+	names := make([]string, 0, len(c.runs))
+	for name := range c.runs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
 ////
 
 func (c *Cron) indexByName(name string) int {
@@ -177,69 +401,161 @@ func (c *Cron) indexByName(name string) int {
 	return -1
 }
 
-func (c *Cron) invoke(entry *JobEntry) {
+// invoke runs a single attempt of the job, enforcing the entry's Timeout.
+func (c *Cron) invoke(entry *JobEntry, ctx context.Context, count, attempt int) (Outcome, error) {
+	jobCtx := &JobContext{
+		Context: ctx,
+		Name:    entry.Name,
+		Count:   count,
+		Attempt: attempt,
+	}
+	if entry.Timeout <= 0 {
+		return c.call(entry, jobCtx)
+	}
+
+	timeout, cancel := withTimeout(ctx, c.clock, entry.Timeout)
+	defer cancel()
+	jobCtx.Context = timeout
+
+	var (
+		outcome  Outcome
+		err      error
+		timedOut bool
+		done     = make(chan struct{})
+		expired  <-chan time.Time
+	)
+	if entry.TimeoutGrace > 0 {
+		grace := c.clock.NewTimer(entry.Timeout + entry.TimeoutGrace)
+		defer grace.Stop()
+		expired = grace.C()
+	}
+	go func() {
+		outcome, err = c.call(entry, jobCtx)
+		// The run timed out only if its deadline passed before the job
+		// returned.
+		timedOut = timeout.stop()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-expired:
+		c.logf("cron: abandoned %s, still running %s after timeout", entry.Name, entry.TimeoutGrace)
+		return OutcomeTimeout, ErrTimeout
+	}
+	if timedOut && outcome != OutcomePanic {
+		return OutcomeTimeout, ErrTimeout
+	}
+	return outcome, err
+}
+
+// call runs the job, recovering from panics.
+func (c *Cron) call(entry *JobEntry, ctx *JobContext) (outcome Outcome, err error) {
 	defer func() {
 		if r := recover(); r != nil {
 			const size = 64 << 10
 			buf := make([]byte, size)
 			buf = buf[:runtime.Stack(buf, false)]
-			c.logf("cron: panic running job: %v\n%s", r, buf)
+			outcome, err = OutcomePanic, fmt.Errorf("cron: panic running job: %v\n%s", r, buf)
 		}
 	}()
-	entry.Count += 1
-	entry.Job.Run(&JobContext{
-		Name:  entry.Name,
-		Count: entry.Count,
-	})
+	if job, ok := entry.Job.(*Job3To2); ok {
+		if err := job.job.Run(ctx); err != nil {
+			return OutcomeError, err
+		}
+		return OutcomeSuccess, nil
+	}
+	entry.Job.Run(ctx)
+	return OutcomeSuccess, nil
+}
+
+// begin records a run of the named job, before its goroutine is started.
+func (c *Cron) begin(name string) {
+	c.runMux.Lock()
+	defer c.runMux.Unlock()
+	c.runs[name]++
+	c.jobs.Add(1)
+}
+
+// finish records the end of a run started by begin.
+func (c *Cron) finish(name string) {
+	c.runMux.Lock()
+	defer c.runMux.Unlock()
+	if c.runs[name]--; c.runs[name] <= 0 {
+		delete(c.runs, name)
+	}
+	c.jobs.Done()
+}
+
+// bind derives the context for the runs of entry from the Cron context.
+func (c *Cron) bind(entry *JobEntry) {
+	entry.ctx, entry.cancel = context.WithCancel(c.ctx)
+	entry.align = c.Locker != nil
 }
 
 func (c *Cron) scheduleJobs() {
 	// Figure out the next activation times for each entry.
 	now := c.now()
+	c.load()
 	for _, entry := range c.entries {
-		entry.NextTime = entry.Schedule.Next(now)
+		c.bind(entry)
+		if c.restore(entry) {
+			c.catchUp(entry, now)
+			c.plan(entry, entry.next(now))
+			c.update(entry)
+		}
 	}
+	c.entries = c.retire(c.entries)
 
 	for {
 		// Determine the next entry to run.
 		sort.Sort(byTime(c.entries))
 
-		var timer *time.Timer
-		if len(c.entries) == 0 || c.entries[0].NextTime.IsZero() {
+		var timer Timer
+		if len(c.entries) == 0 || c.entries[0].RunTime.IsZero() {
 			// If there are no entries yet, just sleep - it still handles new entries
 			// and stop requests.
-			timer = time.NewTimer(100000 * time.Hour)
+			timer = c.clock.NewTimer(100000 * time.Hour)
 		} else {
-			timer = time.NewTimer(c.entries[0].NextTime.Sub(now))
+			timer = c.clock.NewTimer(c.entries[0].RunTime.Sub(now))
 		}
 
 		for {
 			select {
-			case now = <-timer.C:
+			case now = <-timer.C():
 				now = now.In(c.location)
-				// Run every entry whose next time was less than now
+				// Run every entry whose run time was less than now
 				for _, e := range c.entries {
-					if e.NextTime.After(now) || e.NextTime.IsZero() {
+					if e.RunTime.After(now) || e.RunTime.IsZero() {
 						break
 					}
-					go c.invoke(e)
-					e.PrevTime = e.NextTime
-					e.NextTime = e.Schedule.Next(now)
+					c.fire(e, now)
+					c.plan(e, e.next(e.planned(now)))
+					c.update(e)
 				}
+				c.entries = c.retire(c.entries)
 
 			case newEntry := <-c.add:
 				timer.Stop()
 				now = c.now()
-				newEntry.NextTime = newEntry.Schedule.Next(now)
-				c.entries = append(c.entries, newEntry)
+				c.bind(newEntry)
+				if c.restore(newEntry) {
+					c.catchUp(newEntry, now)
+					c.plan(newEntry, newEntry.next(now))
+					c.update(newEntry)
+				}
+				c.entries = c.retire(append(c.entries, newEntry))
 
 			case name := <-c.remove:
+				timer.Stop()
 				p := c.indexByName(name)
 				if p == -1 {
 					break
 				}
 
+				c.entries[p].cancel()
 				c.entries = c.entries[:p+copy(c.entries[p:], c.entries[p+1:])]
+				c.forget(name)
 
 			case <-c.snapshot:
 				c.snapshot <- c.entrySnapshot()
@@ -255,6 +571,65 @@ func (c *Cron) scheduleJobs() {
 	}
 }
 
+// plan sets the next activation time of e, and the time its run is
+// dispatched after applying the Offset and Jitter of the entry.
+func (c *Cron) plan(e *JobEntry, next time.Time) {
+	e.NextTime, e.RunTime = next, next
+	if next.IsZero() {
+		return
+	}
+	e.RunTime = next.Add(e.Offset)
+	if e.Jitter > 0 {
+		e.RunTime = e.RunTime.Add(time.Duration(rand.Int63n(int64(e.Jitter))))
+	}
+}
+
+// planned maps a time on the clock of RunTime back to the clock of NextTime,
+// by removing the delay of the current run.
+func (e *JobEntry) planned(t time.Time) time.Time {
+	return t.Add(e.NextTime.Sub(e.RunTime))
+}
+
+// next returns the next activation of the schedule of e after t, within its
+// NotBefore and NotAfter bounds.
+func (e *JobEntry) next(t time.Time) time.Time {
+	if t.Before(e.NotBefore) {
+		t = e.NotBefore.Add(-time.Nanosecond)
+	}
+	var next time.Time
+	if e.align {
+		next = atOrAfter(e.Schedule, t.Add(time.Nanosecond))
+	} else {
+		next = e.Schedule.Next(t)
+	}
+	if !e.NotAfter.IsZero() && next.After(e.NotAfter) {
+		return time.Time{}
+	}
+	return next
+}
+
+// update retires the entry once it has reached its MaxRuns or has no
+// activation left before its NotAfter, and saves its state to the Store.
+func (c *Cron) update(e *JobEntry) {
+	if e.MaxRuns > 0 && e.Count >= e.MaxRuns || e.NextTime.IsZero() && !e.NotAfter.IsZero() {
+		e.done = true
+		e.NextTime, e.RunTime = time.Time{}, time.Time{}
+	}
+	c.save(e)
+}
+
+// retire drops the retired once entries from the list, while others stay
+// listed as completed. Their runs in progress are left alone.
+func (c *Cron) retire(entries []*JobEntry) []*JobEntry {
+	active := entries[:0]
+	for _, e := range entries {
+		if !e.done || !e.once {
+			active = append(active, e)
+		}
+	}
+	return active
+}
+
 func (c *Cron) logf(format string, args ...interface{}) {
 	if c.ErrorLogger != nil {
 		c.ErrorLogger.Printf(format, args...)
@@ -268,24 +643,53 @@ func (c *Cron) entrySnapshot() []*JobEntry {
 	entries := make([]*JobEntry, len(c.entries))
 	for i, e := range c.entries {
 		entries[i] = &JobEntry{
-			Schedule: e.Schedule,
-			NextTime: e.NextTime,
-			PrevTime: e.PrevTime,
-			Job:      e.Job,
+			Schedule:         e.Schedule,
+			NextTime:         e.NextTime,
+			PrevTime:         e.PrevTime,
+			RunTime:          e.RunTime,
+			Offset:           e.Offset,
+			Jitter:           e.Jitter,
+			Job:              e.Job,
+			Name:             e.Name,
+			Count:            e.Count,
+			NotBefore:        e.NotBefore,
+			NotAfter:         e.NotAfter,
+			MaxRuns:          e.MaxRuns,
+			Completed:        e.done,
+			Timeout:          e.Timeout,
+			TimeoutGrace:     e.TimeoutGrace,
+			Overlap:          e.Overlap,
+			MaxQueued:        e.MaxQueued,
+			Group:            e.Group,
+			Retry:            e.Retry,
+			Misfire:          e.Misfire,
+			MaxMisfires:      e.MaxMisfires,
+			MisfireThreshold: e.MisfireThreshold,
 		}
+		entries[i].LastOutcome, entries[i].LastError = e.state.last()
 	}
 	return entries
 }
 
 // now returns current time in c location
 func (c *Cron) now() time.Time {
-	return time.Now().In(c.location)
+	return c.clock.Now().In(c.location)
 }
 
 func (c *Cron) makeName(names []string) string {
 	if len(names) <= 0 {
-		return fmt.Sprintf("%d", time.Now().Unix())
+		return fmt.Sprintf("%d-%d", time.Now().Unix(), atomic.AddUint64(&c.names, 1))
 	} else {
 		return names[0]
 	}
 }
+
+// entryName returns the name of an entry added by a spec based method, which
+// must be given when a Store is set: generated names change on every start,
+// so the stored state would never be restored.
+func (c *Cron) entryName(names []string) (string, error) {
+	if len(names) == 0 && c.Store != nil {
+		return "", ErrNameRequired
+	}
+	return c.makeName(names), nil
+}
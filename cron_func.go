@@ -1,5 +1,7 @@
 package cron
 
+import "time"
+
 //
 // Author: 陈永佳 chenyongjia@parkingwang.com, yoojiachen@gmail.com
 // Func Job supports for cron
@@ -7,32 +9,69 @@ package cron
 
 // AddFunc adds a func to the Cron to be run on the given schedule.
 func (c *Cron) AddFunc(spec string, funcJob func(), names ...string) error {
-	name := c.makeName(names)
-	return c.AddJob(spec, JobWrapper(funcJob), name)
+	return c.AddJob(spec, JobWrapper(funcJob), names...)
 }
 
 // AddFunc adds a func to the Cron to be run on the given schedule.
 func (c *Cron) AddOnceFunc(spec string, funcJob func(), names ...string) error {
-	name := c.makeName(names)
-	// Support Once run function.
-	return c.AddJob(spec, JobWrapper(func() {
-		defer c.Remove(name)
-		funcJob()
-	}), name)
+	return c.addOnce(spec, &Job2To1{job: JobWrapper(funcJob)}, names)
 }
 
 // AddFunc adds a func to the Cron to be run on the given schedule.
 func (c *Cron) AddFunc2(spec string, funcJob func(ctx *JobContext), names ...string) error {
-	name := c.makeName(names)
-	return c.AddJob2(spec, Job2Wrapper(funcJob), name)
+	return c.AddJob2(spec, Job2Wrapper(funcJob), names...)
 }
 
 // AddFunc adds a func to the Cron to be run on the given schedule.
 func (c *Cron) AddOnceFunc2(spec string, funcJob func(ctx *JobContext), names ...string) error {
-	name := c.makeName(names)
-	// Support Once run function.
-	return c.AddJob2(spec, Job2Wrapper(func(ctx *JobContext) {
-		defer c.Remove(name)
-		funcJob(ctx)
-	}), name)
+	return c.addOnce(spec, Job2Wrapper(funcJob), names)
+}
+
+// addOnce adds a job retired by the scheduler after its first run. With a
+// Store, it is not run again after a restart.
+func (c *Cron) addOnce(spec string, job Job2, names []string) error {
+	name, err := c.entryName(names)
+	if err != nil {
+		return err
+	}
+	schedule, err := ParseWithName(spec, name)
+	if err != nil {
+		return err
+	}
+	c.AddEntry(&JobEntry{
+		Schedule:  schedule,
+		Job:       job,
+		Name:      name,
+		MaxRuns:   1,
+		once:      true,
+		generated: len(names) == 0,
+	})
+	return nil
+}
+
+// AddTimeoutFunc2 adds a func to the Cron to be run on the given schedule.
+// The context of each run is cancelled once timeout expires.
+func (c *Cron) AddTimeoutFunc2(spec string, timeout time.Duration, funcJob func(ctx *JobContext), names ...string) error {
+	name, err := c.entryName(names)
+	if err != nil {
+		return err
+	}
+	schedule, err := ParseWithName(spec, name)
+	if err != nil {
+		return err
+	}
+	c.AddEntry(&JobEntry{
+		Schedule:  schedule,
+		Job:       Job2Wrapper(funcJob),
+		Name:      name,
+		Timeout:   timeout,
+		generated: len(names) == 0,
+	})
+	return nil
+}
+
+// AddFunc3 adds a func to the Cron to be run on the given schedule.
+// A returned error marks the run as failed.
+func (c *Cron) AddFunc3(spec string, funcJob func(ctx *JobContext) error, names ...string) error {
+	return c.AddJob3(spec, Job3Wrapper(funcJob), names...)
 }
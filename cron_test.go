@@ -1,8 +1,13 @@
 package cron
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"reflect"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 )
@@ -467,6 +472,481 @@ func TestJobWithZeroTimeDoesNotRun(t *testing.T) {
 	}
 }
 
+// startFake starts a Cron on a FakeClock with the given entry, hourly, and
+// fires its first run.
+func startFake(t *testing.T, entry *JobEntry) (*Cron, *FakeClock) {
+	t.Helper()
+	clock := NewFakeClock(getTime("Mon Jul 9 14:45 2012"))
+	cron := NewWithClock(time.UTC, clock)
+	entry.Schedule = mustParse(t, "0 0 * * * ?")
+	cron.AddEntry(entry)
+	cron.Start()
+	clock.BlockUntil(1)
+	clock.Set(getTime("Mon Jul 9 15:00 2012"))
+	return cron, clock
+}
+
+// Test that stopping the cron cancels the context of running jobs.
+func TestStopCancelsJobContext(t *testing.T) {
+	started := make(chan struct{})
+	done := make(chan struct{})
+
+	cron, _ := startFake(t, &JobEntry{Name: "long", Job: Job2Wrapper(func(ctx *JobContext) {
+		close(started)
+		<-ctx.Done()
+		close(done)
+	})})
+
+	<-started
+	cron.Stop()
+
+	select {
+	case <-time.After(OneSecond):
+		t.Error("expected job context cancelled on Stop")
+	case <-done:
+	}
+}
+
+// Test that removing an entry cancels the context of its running jobs.
+func TestRemoveCancelsJobContext(t *testing.T) {
+	started := make(chan struct{})
+	done := make(chan struct{})
+
+	cron, _ := startFake(t, &JobEntry{Name: "long", Job: Job2Wrapper(func(ctx *JobContext) {
+		close(started)
+		<-ctx.Done()
+		close(done)
+	})})
+	defer cron.Stop()
+
+	<-started
+	cron.Remove("long")
+
+	select {
+	case <-time.After(OneSecond):
+		t.Error("expected job context cancelled on Remove")
+	case <-done:
+	}
+}
+
+// Test that the job context is cancelled once the entry timeout expires.
+func TestTimeoutCancelsJobContext(t *testing.T) {
+	errs := make(chan error, 1)
+
+	cron, clock := startFake(t, &JobEntry{Name: "timeout", Timeout: 100 * time.Millisecond,
+		Job: Job2Wrapper(func(ctx *JobContext) {
+			<-ctx.Done()
+			errs <- ctx.Err()
+		})})
+	defer cron.Stop()
+
+	// The next activation and the timeout.
+	clock.BlockUntil(2)
+	clock.Advance(100 * time.Millisecond)
+
+	select {
+	case <-time.After(OneSecond):
+		t.Fatal("expected job context cancelled by timeout")
+	case err := <-errs:
+		if err != context.DeadlineExceeded {
+			t.Errorf("expected %v, got %v", context.DeadlineExceeded, err)
+		}
+	}
+}
+
+// Test that Shutdown waits for running jobs to return.
+func TestShutdownWaitsForJobs(t *testing.T) {
+	started := make(chan struct{})
+	release := make(chan struct{})
+	var finished int32
+
+	cron, _ := startFake(t, &JobEntry{Name: "slow", Job: Job2Wrapper(func(ctx *JobContext) {
+		close(started)
+		<-release
+		atomic.StoreInt32(&finished, 1)
+	})})
+
+	<-started
+	done := make(chan error)
+	go func() { done <- cron.Shutdown(context.Background()) }()
+	waitUntil(t, "shutdown to begin", func() bool { return closed(cron.draining()) })
+	select {
+	case err := <-done:
+		t.Fatalf("expected Shutdown to wait for running job, returned %v", err)
+	default:
+	}
+
+	close(release)
+	if err := <-done; err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if atomic.LoadInt32(&finished) == 0 {
+		t.Error("expected Shutdown to wait for running job")
+	}
+}
+
+// Test that Shutdown lets running jobs finish instead of cancelling them.
+func TestShutdownDrainsJobs(t *testing.T) {
+	started := make(chan struct{})
+	release := make(chan struct{})
+	result := make(chan error, 1)
+
+	cron, _ := startFake(t, &JobEntry{Name: "draining", Job: Job2Wrapper(func(ctx *JobContext) {
+		close(started)
+		select {
+		case <-ctx.Done():
+			result <- ctx.Err()
+		case <-release:
+			result <- nil
+		}
+	})})
+
+	<-started
+	done := make(chan error)
+	go func() { done <- cron.Shutdown(context.Background()) }()
+	waitUntil(t, "shutdown to begin", func() bool { return closed(cron.draining()) })
+	close(release)
+	if err := <-done; err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if err := <-result; err != nil {
+		t.Errorf("expected job finishes normally, got %v", err)
+	}
+}
+
+// Test that Shutdown cancels the jobs still running once ctx is done.
+func TestShutdownCancelsOnTimeout(t *testing.T) {
+	started := make(chan struct{})
+	result := make(chan error, 1)
+
+	cron, _ := startFake(t, &JobEntry{Name: "cancelled", Job: Job2Wrapper(func(ctx *JobContext) {
+		close(started)
+		<-ctx.Done()
+		result <- ctx.Err()
+	})})
+
+	<-started
+	ctx, cancel := context.WithDeadline(context.Background(), time.Now())
+	defer cancel()
+	if err := cron.Shutdown(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected deadline error, got %v", err)
+	}
+	select {
+	case err := <-result:
+		if err != context.Canceled {
+			t.Errorf("expected job context cancelled, got %v", err)
+		}
+	case <-time.After(OneSecond):
+		t.Error("expected job cancelled after shutdown timeout")
+	}
+}
+
+// Test that Shutdown reports the jobs still running when it gives up.
+func TestShutdownTimeout(t *testing.T) {
+	started := make(chan struct{})
+	release := make(chan struct{})
+	defer close(release)
+
+	cron, _ := startFake(t, &JobEntry{Name: "stuck", Job: Job2Wrapper(func(ctx *JobContext) {
+		close(started)
+		<-release
+	})})
+
+	<-started
+	ctx, cancel := context.WithDeadline(context.Background(), time.Now())
+	defer cancel()
+	err := cron.Shutdown(ctx)
+	if err == nil || !errors.Is(err, context.DeadlineExceeded) || !strings.Contains(err.Error(), "stuck") {
+		t.Errorf("expected deadline error naming stuck job, got %v", err)
+	}
+	if running := cron.Running(); len(running) != 1 || running[0] != "stuck" {
+		t.Errorf("expected [stuck] running, got %v", running)
+	}
+}
+
+// overlapJob records the number of runs started and the maximum number of
+// runs active at once. Runs last until release is closed or they are
+// cancelled.
+type overlapJob struct {
+	mux       sync.Mutex
+	release   chan struct{}
+	started   int
+	active    int
+	maxActive int
+	cancelled int
+}
+
+func (j *overlapJob) Run(ctx *JobContext) {
+	j.mux.Lock()
+	j.started++
+	j.active++
+	if j.active > j.maxActive {
+		j.maxActive = j.active
+	}
+	j.mux.Unlock()
+
+	select {
+	case <-j.release:
+	case <-ctx.Done():
+		j.mux.Lock()
+		j.cancelled++
+		j.mux.Unlock()
+	}
+
+	j.mux.Lock()
+	j.active--
+	j.mux.Unlock()
+}
+
+func (j *overlapJob) stats() (started, maxActive, cancelled int) {
+	j.mux.Lock()
+	defer j.mux.Unlock()
+	return j.started, j.maxActive, j.cancelled
+}
+
+// Test that each overlap policy treats a slow job as documented.
+func TestOverlapPolicies(t *testing.T) {
+	tests := []struct {
+		policy                   OverlapPolicy
+		starts                   [3]int
+		started, maxActive       int
+		cancelled, allowedJitter int
+	}{
+		{OverlapAllow, [3]int{1, 2, 3}, 3, 3, 0, 0},
+		{OverlapSkip, [3]int{1, 1, 1}, 1, 1, 0, 0},
+		// The third run is dropped, as one is already queued.
+		{OverlapQueue, [3]int{1, 1, 1}, 2, 1, 0, 0},
+		// The replaced run may not have returned yet when the new one starts.
+		{OverlapReplace, [3]int{1, 2, 3}, 3, 1, 2, 1},
+	}
+
+	for _, test := range tests {
+		job := &overlapJob{release: make(chan struct{})}
+		clock := NewFakeClock(getTime("Mon Jul 9 14:45 2012"))
+
+		cron := NewWithClock(time.UTC, clock)
+		cron.AddEntry(&JobEntry{
+			Schedule: mustParse(t, "0 0 * * * ?"),
+			Job:      job,
+			Name:     test.policy.String(),
+			Overlap:  test.policy,
+		})
+		cron.Start()
+
+		// Fire three runs while the first one is still active.
+		for i, at := range []string{"Mon Jul 9 15:00 2012", "Mon Jul 9 16:00 2012", "Mon Jul 9 17:00 2012"} {
+			clock.BlockUntil(1)
+			clock.Set(getTime(at))
+			clock.BlockUntil(1)
+			waitUntil(t, "runs to start", func() bool {
+				started, _, _ := job.stats()
+				return started == test.starts[i]
+			})
+		}
+		close(job.release)
+		waitUntil(t, "runs to finish", func() bool { return len(cron.Running()) == 0 })
+		started, maxActive, cancelled := job.stats()
+		cron.Stop()
+
+		if started != test.started || cancelled != test.cancelled ||
+			maxActive < test.maxActive || maxActive > test.maxActive+test.allowedJitter {
+			t.Errorf("%s: (expected) started %d, max active %d, cancelled %d != %d, %d, %d (actual)",
+				test.policy, test.started, test.maxActive, test.cancelled, started, maxActive, cancelled)
+		}
+		if entries := cron.Entries(); entries[0].Overlap != test.policy {
+			t.Errorf("%s: expected policy in entries, got %s", test.policy, entries[0].Overlap)
+		}
+	}
+}
+
+// Test that Shutdown drops the runs queued by OverlapQueue instead of
+// starting them once the active run returns.
+func TestShutdownDropsQueuedRuns(t *testing.T) {
+	job := &overlapJob{release: make(chan struct{})}
+	clock := NewFakeClock(getTime("Mon Jul 9 14:45 2012"))
+
+	cron := NewWithClock(time.UTC, clock)
+	cron.AddEntry(&JobEntry{
+		Schedule: mustParse(t, "0 0 * * * ?"),
+		Job:      job,
+		Name:     "queued",
+		Overlap:  OverlapQueue,
+	})
+	cron.Start()
+
+	// Start a run, and queue another behind it.
+	for _, at := range []string{"Mon Jul 9 15:00 2012", "Mon Jul 9 16:00 2012"} {
+		clock.BlockUntil(1)
+		clock.Set(getTime(at))
+		clock.BlockUntil(1)
+	}
+	waitUntil(t, "run to start", func() bool {
+		started, _, _ := job.stats()
+		return started == 1
+	})
+
+	done := make(chan error)
+	go func() { done <- cron.Shutdown(context.Background()) }()
+	waitUntil(t, "shutdown to begin", func() bool { return closed(cron.draining()) })
+	close(job.release)
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+	if started, _, _ := job.stats(); started != 1 {
+		t.Errorf("expected the queued run to be dropped, got %d runs", started)
+	}
+}
+
+// waitUntil polls cond until it holds, failing the test after a second.
+func waitUntil(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(OneSecond)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+// Test that the outcome of the last run is reported by Entries.
+func TestLastOutcome(t *testing.T) {
+	tests := []struct {
+		job      Job2
+		timeout  time.Duration
+		expected Outcome
+	}{
+		{Job2Wrapper(func(ctx *JobContext) {}), 0, OutcomeSuccess},
+		{NewJob3To2(Job3Wrapper(func(ctx *JobContext) error { return errors.New("failed") })), 0, OutcomeError},
+		{Job2Wrapper(func(ctx *JobContext) { panic("YOLO") }), 0, OutcomePanic},
+		{Job2Wrapper(func(ctx *JobContext) { <-ctx.Done() }), 10 * time.Second, OutcomeTimeout},
+	}
+
+	for _, test := range tests {
+		clock := NewFakeClock(getTime("Mon Jul 9 14:45 2012"))
+		cron := NewWithClock(time.UTC, clock)
+		cron.AddEntry(&JobEntry{Schedule: mustParse(t, "0 0 * * * ?"), Job: test.job, Timeout: test.timeout})
+		cron.Start()
+
+		clock.BlockUntil(1)
+		clock.Set(getTime("Mon Jul 9 15:00 2012"))
+		if test.timeout > 0 {
+			// The next activation and the timeout.
+			clock.BlockUntil(2)
+			clock.Advance(test.timeout)
+		}
+		waitUntil(t, "the run to finish", func() bool { return cron.Entries()[0].LastOutcome != OutcomeNone })
+
+		entry := cron.Entries()[0]
+		if entry.LastOutcome != test.expected {
+			t.Errorf("(expected) %s != %s (actual)", test.expected, entry.LastOutcome)
+		}
+		if test.expected == OutcomeTimeout && entry.LastError != ErrTimeout {
+			t.Errorf("expected %v, got %v", ErrTimeout, entry.LastError)
+		}
+		cron.Stop()
+	}
+}
+
+// Test that a run ignoring its timeout is abandoned after the grace period,
+// so that the runs queued behind it proceed.
+func TestTimeoutGraceAbandons(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+	var (
+		mux    sync.Mutex
+		counts []int
+	)
+
+	clock := NewFakeClock(getTime("Mon Jul 9 14:45 2012"))
+	cron := NewWithClock(time.UTC, clock)
+	cron.AddEntry(&JobEntry{
+		Schedule:     mustParse(t, "0 0 * * * ?"),
+		Name:         "stubborn",
+		Overlap:      OverlapQueue,
+		Timeout:      10 * time.Second,
+		TimeoutGrace: 5 * time.Second,
+		Job: Job2Wrapper(func(ctx *JobContext) {
+			mux.Lock()
+			counts = append(counts, ctx.Count)
+			mux.Unlock()
+			<-release
+		}),
+	})
+	cron.Start()
+	defer cron.Stop()
+
+	clock.BlockUntil(1)
+	clock.Set(getTime("Mon Jul 9 15:00 2012"))
+	// The next activation, the timeout and the grace period of the first run.
+	clock.BlockUntil(3)
+	// The second run is due while the first one is stuck, past its grace
+	// period; it runs once the first one is abandoned, and is abandoned too.
+	clock.Set(getTime("Mon Jul 9 16:00 2012"))
+	clock.BlockUntil(3)
+	clock.Advance(15 * time.Second)
+	waitUntil(t, "runs to be abandoned", func() bool { return len(cron.Running()) == 0 })
+
+	mux.Lock()
+	defer mux.Unlock()
+	if len(counts) != 2 {
+		t.Errorf("expected 2 runs despite stuck job, got %v", counts)
+	}
+}
+
+// Test that job timeouts and their grace period follow the Cron clock.
+func TestTimeoutFakeClock(t *testing.T) {
+	clock := NewFakeClock(getTime("Mon Jul 9 14:45 2012"))
+	release := make(chan struct{})
+	defer close(release)
+	errs := make(chan error, 1)
+
+	cron := NewWithClock(time.UTC, clock)
+	cron.AddEntry(&JobEntry{
+		Schedule:     mustParse(t, "0 0 * * * ?"),
+		Name:         "timed",
+		Timeout:      10 * time.Second,
+		TimeoutGrace: 5 * time.Second,
+		Job: Job2Wrapper(func(ctx *JobContext) {
+			<-ctx.Done()
+			errs <- ctx.Err()
+			<-release
+		}),
+	})
+	cron.Start()
+	defer cron.Stop()
+
+	clock.BlockUntil(1)
+	clock.Set(getTime("Mon Jul 9 15:00 2012"))
+	// The next activation, the timeout and the grace period.
+	clock.BlockUntil(3)
+	clock.Advance(9 * time.Second)
+	select {
+	case err := <-errs:
+		t.Fatalf("expected no timeout before 10s, got %v", err)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	clock.Advance(time.Second)
+	select {
+	case err := <-errs:
+		if err != context.DeadlineExceeded {
+			t.Errorf("expected deadline exceeded, got %v", err)
+		}
+	case <-time.After(OneSecond):
+		t.Fatal("expected job context cancelled after the timeout")
+	}
+	if running := cron.Running(); len(running) != 1 {
+		t.Errorf("expected run still running during the grace period, got %v", running)
+	}
+
+	clock.Advance(5 * time.Second)
+	waitUntil(t, "run abandoned after the grace period", func() bool { return len(cron.Running()) == 0 })
+	if entries := cron.Entries(); entries[0].LastOutcome != OutcomeTimeout {
+		t.Errorf("expected timeout outcome, got %v", entries[0].LastOutcome)
+	}
+}
+
 func wait(wg *sync.WaitGroup) chan bool {
 	ch := make(chan bool)
 	go func() {
@@ -484,3 +964,219 @@ func stop(cron *Cron) chan bool {
 	}()
 	return ch
 }
+
+// Hash tokens are seeded with the name of the entry.
+func TestAddJobHashedByName(t *testing.T) {
+	cron := New()
+	for _, name := range []string{"report", "backup"} {
+		if err := cron.AddFunc("0 H/15 * * * ?", func() {}, name); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for _, entry := range cron.Entries() {
+		expected, _ := ParseWithName("0 H/15 * * * ?", entry.Name)
+		if !reflect.DeepEqual(entry.Schedule, expected) {
+			t.Errorf("%s: expected %+v, got %+v", entry.Name, expected, entry.Schedule)
+		}
+	}
+}
+
+// Test that Offset delays the runs of an entry while NextTime keeps the
+// planned time.
+func TestOffset(t *testing.T) {
+	clock := NewFakeClock(time.Date(2012, time.July, 9, 14, 45, 0, 0, time.UTC))
+	runs := make(chan time.Time, 10)
+
+	cron := NewWithClock(time.UTC, clock)
+	cron.AddEntry(&JobEntry{
+		Schedule: Every(time.Hour),
+		Job:      Job2Wrapper(func(ctx *JobContext) { runs <- clock.Now() }),
+		Name:     "offset",
+		Offset:   30 * time.Second,
+	})
+	cron.Schedule(mustParse(t, "0 0 * * * ?"), JobWrapper(func() {}), "hourly")
+	cron.Start()
+	defer cron.Stop()
+
+	entries := cron.Entries()
+	if entries[0].Name != "offset" {
+		entries[0], entries[1] = entries[1], entries[0]
+	}
+	if !entries[0].NextTime.Equal(getTime("Mon Jul 9 15:45 2012")) ||
+		!entries[0].RunTime.Equal(getTime("Mon Jul 9 15:45:30 2012")) {
+		t.Errorf("unexpected next %v, run %v", entries[0].NextTime, entries[0].RunTime)
+	}
+	if !entries[1].RunTime.Equal(entries[1].NextTime) {
+		t.Errorf("expected run time %v, got %v", entries[1].NextTime, entries[1].RunTime)
+	}
+
+	clock.BlockUntil(1)
+	clock.Set(getTime("Mon Jul 9 15:45 2012"))
+	select {
+	case actual := <-runs:
+		t.Fatalf("expected no run before the offset, got one at %v", actual)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	clock.BlockUntil(1)
+	clock.Set(getTime("Mon Jul 9 15:45:30 2012"))
+	select {
+	case <-time.After(OneSecond):
+		t.Fatal("expected job runs after the offset")
+	case actual := <-runs:
+		if !actual.Equal(getTime("Mon Jul 9 15:45:30 2012")) {
+			t.Errorf("unexpected run at %v", actual)
+		}
+	}
+
+	clock.BlockUntil(1)
+	for _, entry := range cron.Entries() {
+		if entry.Name == "offset" && !entry.NextTime.Equal(getTime("Mon Jul 9 16:45 2012")) {
+			t.Errorf("unexpected next %v", entry.NextTime)
+		}
+	}
+}
+
+// Test that Jitter spreads the runs of identical schedules within the window.
+func TestJitter(t *testing.T) {
+	const n = 10
+	clock := NewFakeClock(time.Date(2012, time.July, 9, 14, 45, 0, 0, time.UTC))
+	runs := make(chan time.Time, n)
+
+	cron := NewWithClock(time.UTC, clock)
+	for i := 0; i < n; i++ {
+		cron.AddEntry(&JobEntry{
+			Schedule: mustParse(t, "0 0 * * * ?"),
+			Job:      Job2Wrapper(func(ctx *JobContext) { runs <- clock.Now() }),
+			Name:     fmt.Sprintf("jitter-%d", i),
+			Jitter:   30 * time.Second,
+		})
+	}
+	cron.Start()
+	defer cron.Stop()
+
+	var (
+		planned  = getTime("Mon Jul 9 15:00 2012")
+		distinct = make(map[time.Time]bool)
+	)
+	for _, entry := range cron.Entries() {
+		if !entry.NextTime.Equal(planned) {
+			t.Errorf("%s: expected next %v, got %v", entry.Name, planned, entry.NextTime)
+		}
+		if entry.RunTime.Before(planned) || !entry.RunTime.Before(planned.Add(30*time.Second)) {
+			t.Errorf("%s: run time %v outside of the jitter window", entry.Name, entry.RunTime)
+		}
+		distinct[entry.RunTime] = true
+	}
+	if len(distinct) < 2 {
+		t.Errorf("expected jittered run times to differ, got %v", distinct)
+	}
+
+	clock.BlockUntil(1)
+	clock.Set(planned.Add(30 * time.Second))
+	for i := 0; i < n; i++ {
+		select {
+		case <-time.After(OneSecond):
+			t.Fatalf("expected %d runs, got %d", n, i)
+		case <-runs:
+		}
+	}
+}
+
+// Test that NotBefore and NotAfter bound the runs of an entry, which is then
+// reported as completed.
+func TestBoundedEntry(t *testing.T) {
+	clock := NewFakeClock(time.Date(2012, time.July, 9, 14, 45, 0, 0, time.UTC))
+	runs := make(chan time.Time, 10)
+
+	cron := NewWithClock(time.UTC, clock)
+	cron.AddEntry(&JobEntry{
+		Schedule:  mustParse(t, "0 0 * * * ?"),
+		Job:       Job2Wrapper(func(ctx *JobContext) { runs <- clock.Now() }),
+		Name:      "bounded",
+		NotBefore: getTime("Mon Jul 9 16:30 2012"),
+		NotAfter:  getTime("Mon Jul 9 18:00 2012"),
+	})
+	cron.Start()
+	defer cron.Stop()
+
+	if next := cron.Entries()[0].NextTime; !next.Equal(getTime("Mon Jul 9 17:00 2012")) {
+		t.Errorf("expected first run after NotBefore, got %v", next)
+	}
+	for _, at := range []string{"Mon Jul 9 17:00 2012", "Mon Jul 9 18:00 2012"} {
+		clock.BlockUntil(1)
+		clock.Set(getTime(at))
+		select {
+		case <-time.After(OneSecond):
+			t.Fatalf("expected job runs at %s", at)
+		case actual := <-runs:
+			if !actual.Equal(getTime(at)) {
+				t.Errorf("unexpected run at %v", actual)
+			}
+		}
+	}
+
+	clock.BlockUntil(1)
+	entries := cron.Entries()
+	if len(entries) != 1 || !entries[0].Completed || !entries[0].NextTime.IsZero() || entries[0].Count != 2 {
+		t.Errorf("expected completed entry after 2 runs, got %+v", entries[0])
+	}
+
+	cron.Remove("bounded")
+	waitUntil(t, "completed entry removed", func() bool { return len(cron.Entries()) == 0 })
+}
+
+// Test that an entry added past its NotAfter never runs, and is reported as
+// completed.
+func TestExpiredEntry(t *testing.T) {
+	clock := NewFakeClock(time.Date(2012, time.July, 9, 14, 45, 0, 0, time.UTC))
+	cron := NewWithClock(time.UTC, clock)
+	cron.AddEntry(&JobEntry{
+		Schedule: mustParse(t, "0 0 * * * ?"),
+		Job:      Job2Wrapper(func(ctx *JobContext) { t.Error("expected no run") }),
+		Name:     "expired",
+		NotAfter: getTime("Mon Jul 9 14:00 2012"),
+	})
+	cron.Start()
+	defer cron.Stop()
+
+	entries := cron.Entries()
+	if len(entries) != 1 || !entries[0].Completed || !entries[0].NextTime.IsZero() || entries[0].Count != 0 {
+		t.Errorf("expected a completed entry without runs, got %v", entries)
+	}
+}
+
+// Test that a once job is dropped after its run, freeing its name.
+func TestOnceEntryRetired(t *testing.T) {
+	clock := NewFakeClock(time.Date(2012, time.July, 9, 14, 45, 0, 0, time.UTC))
+	runs := make(chan int, 10)
+
+	cron := NewWithClock(time.UTC, clock)
+	cron.AddOnceFunc2("0 0 * * * ?", func(ctx *JobContext) { runs <- ctx.Count }, "once")
+	cron.Start()
+	defer cron.Stop()
+
+	clock.BlockUntil(1)
+	clock.Set(getTime("Mon Jul 9 15:00 2012"))
+	<-runs
+	clock.BlockUntil(1)
+	if entries := cron.Entries(); len(entries) != 0 {
+		t.Fatalf("expected once entry dropped after its run, got %v", entries)
+	}
+
+	cron.AddOnceFunc2("0 0 * * * ?", func(ctx *JobContext) { runs <- ctx.Count }, "once")
+	waitUntil(t, "once entry added again", func() bool { return len(cron.Entries()) == 1 })
+	if entries := cron.Entries(); entries[0].Name != "once" {
+		t.Fatalf("expected once entry added again, got %v", entries)
+	}
+	clock.Set(getTime("Mon Jul 9 16:00 2012"))
+	select {
+	case count := <-runs:
+		if count != 1 {
+			t.Errorf("expected a fresh count, got %d", count)
+		}
+	case <-time.After(OneSecond):
+		t.Fatal("expected once entry added again to run")
+	}
+}
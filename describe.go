@@ -0,0 +1,362 @@
+package cron
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Locale holds the phrases Describe uses to describe schedules in a language.
+// Formats take the arguments noted for each; indexed verbs such as %[2]s may
+// reorder them.
+type Locale struct {
+	// Names of the months, January first, and days of week, Sunday first.
+	Months   [12]string
+	Weekdays [7]string
+
+	// Ordinals from the first to the fifth, for "FRI#2".
+	Ordinals [5]string
+
+	// ListSep separates the items of a list, and ListLast the last two.
+	ListSep, ListLast string
+
+	// Or joins the day of month and day of week, when both are restricted
+	// and a day matches either; And when one of them has a star, as in
+	// "*/2", and a day must match both.
+	Or, And string
+
+	// Phrases for each field.
+	Second, Minute, Hour, Dom, Month, Dow, Year FieldPhrases
+
+	// At describes times of day, such as "09:30", given as a list.
+	At string
+
+	// Day modifiers: Last takes no argument, LastOffset the n of "L-n",
+	// Weekday the n of "nW", LastWeekday none, LastDow the weekday of "dL" and
+	// NthDow the ordinal and weekday of "d#n".
+	Last, LastOffset, Weekday, LastWeekday, LastDow, NthDow string
+
+	// Every describes a ConstantDelaySchedule, given its delay.
+	Every string
+
+	// Never describes a SpecSchedule which never activates, because a field
+	// has no values, such as the zero SpecSchedule.
+	Never string
+
+	// Zone adds the time zone of a schedule, given the description and the
+	// name of the zone.
+	Zone string
+
+	// Compose joins the phrases of the fields into a description.
+	Compose func(p Phrases) string
+}
+
+// FieldPhrases describe the values of a field. Every takes no argument, and
+// is left empty to leave the field out. One takes the value; if empty, Range
+// is used from the value to itself. Step takes the step, StepFrom the step and
+// the first value, Range the first and last values, and List the values.
+type FieldPhrases struct {
+	Every, One, Step, StepFrom, Range, List string
+}
+
+// Phrases are the localized phrases for the fields of a schedule, empty for
+// fields left out. Day holds the day of month and day of week.
+type Phrases struct {
+	Second, Minute, Hour, Day, Month, Year string
+}
+
+var (
+	localeMux sync.RWMutex
+	locales   = map[string]*Locale{
+		"en": English,
+		"zh": Chinese,
+	}
+)
+
+// RegisterLocale makes a locale available to Describe under the given
+// language tag, replacing any locale registered before for it.
+func RegisterLocale(lang string, l *Locale) {
+	localeMux.Lock()
+	defer localeMux.Unlock()
+	locales[strings.ToLower(lang)] = l
+}
+
+// lookupLocale returns the locale for a language tag such as "zh" or "zh-CN",
+// falling back to the base language and then to English.
+func lookupLocale(lang string) *Locale {
+	localeMux.RLock()
+	defer localeMux.RUnlock()
+	lang = strings.ToLower(lang)
+	if l, ok := locales[lang]; ok {
+		return l
+	}
+	if i := strings.IndexAny(lang, "-_"); i >= 0 {
+		if l, ok := locales[lang[:i]]; ok {
+			return l
+		}
+	}
+	return locales["en"]
+}
+
+// Describe returns a human-readable description of the schedule in the given
+// language, such as "en" or "zh". Languages without a registered locale are
+// described in English. Schedules other than SpecSchedule and
+// ConstantDelaySchedule are described by their String method, if any.
+func Describe(schedule Schedule, lang string) string {
+	l := lookupLocale(lang)
+	switch s := schedule.(type) {
+	case *SpecSchedule:
+		description := l.Never
+		if !s.never() {
+			description = l.Compose(l.phrases(s))
+		}
+		if s.Location != nil {
+			description = fmt.Sprintf(l.Zone, description, s.Location)
+		}
+		return description
+	case ConstantDelaySchedule:
+		return capitalize(fmt.Sprintf(l.Every, shortDuration(s.Delay)))
+	case fmt.Stringer:
+		return s.String()
+	}
+	return fmt.Sprintf("%T", schedule)
+}
+
+// never reports whether a field of s has no values, so that it never
+// activates.
+func (s *SpecSchedule) never() bool {
+	days := s.Dom | s.Dow | s.LastDom | s.WeekdayDom | s.LastDow | s.NthDow
+	return s.Second&^starBit == 0 || s.Minute&^starBit == 0 || s.Hour&^starBit == 0 ||
+		s.Month&^starBit == 0 || days&^starBit == 0 && !s.LastWeekdayDom
+}
+
+// phrases returns the phrases for the fields of s.
+func (l *Locale) phrases(s *SpecSchedule) Phrases {
+	var (
+		number  = func(v uint) string { return fmt.Sprint(v) }
+		month   = func(v uint) string { return l.Months[v-1] }
+		weekday = func(v uint) string { return l.Weekdays[v] }
+		hour    = func(v uint) string { return fmt.Sprintf("%02d:00", v) }
+		hourEnd = func(v uint) string { return fmt.Sprintf("%02d:59", v) }
+
+		secs = fieldValues(s.Second, seconds)
+		mins = fieldValues(s.Minute, minutes)
+		hrs  = fieldValues(s.Hour, hours)
+		p    Phrases
+	)
+
+	// Times of day, such as "09:30", when the second and minute are fixed.
+	secondShape, _ := shapeOf(secs, seconds)
+	minuteShape, _ := shapeOf(mins, minutes)
+	hourShape, _ := shapeOf(hrs, hours)
+	if secondShape == shapeOne && minuteShape == shapeOne && (hourShape == shapeOne || hourShape == shapeList) {
+		times := make([]string, len(hrs))
+		for i, h := range hrs {
+			times[i] = fmt.Sprintf("%02d:%02d", h, mins[0])
+			if secs[0] != 0 {
+				times[i] += fmt.Sprintf(":%02d", secs[0])
+			}
+		}
+		p.Hour = fmt.Sprintf(l.At, l.list(times))
+	} else {
+		if secondShape != shapeOne || secs[0] != 0 {
+			p.Second = l.field(l.Second, secs, seconds, number, number)
+		}
+		if minuteShape != shapeEvery || secondShape == shapeOne {
+			p.Minute = l.field(l.Minute, mins, minutes, number, number)
+		}
+		p.Hour = l.field(l.Hour, hrs, hours, hour, hourEnd)
+	}
+
+	// Days, which match both the day of month and day of week if either has
+	// a star, as "*" and "*/2" do, or any of them otherwise. A field with
+	// every day of its range is left out.
+	domPhrase := l.days(l.field(l.Dom, fieldValues(s.Dom, dom), dom, number, number), l.domModifiers(s))
+	dowPhrase := l.days(l.field(l.Dow, fieldValues(s.Dow, dow), dow, weekday, weekday), l.dowModifiers(s))
+	switch {
+	case domPhrase == "" || dowPhrase == "":
+		p.Day = domPhrase + dowPhrase
+	case s.Dom&starBit > 0 || s.Dow&starBit > 0:
+		p.Day = domPhrase + l.And + dowPhrase
+	default:
+		p.Day = domPhrase + l.Or + dowPhrase
+	}
+
+	p.Month = l.field(l.Month, fieldValues(s.Month, months), months, month, month)
+	if !s.Year.IsZero() {
+		var values []uint
+		for year := years.min; year <= years.max; year++ {
+			if s.Year.Contains(int(year)) {
+				values = append(values, year)
+			}
+		}
+		p.Year = l.field(l.Year, values, years, number, number)
+	}
+	return p
+}
+
+// days joins the phrase for the values of a day field with its modifiers.
+func (l *Locale) days(phrase string, modifiers []string) string {
+	if phrase != "" {
+		modifiers = append([]string{phrase}, modifiers...)
+	}
+	return strings.Join(modifiers, l.Or)
+}
+
+// domModifiers returns the phrases for the day of month modifiers of s.
+func (l *Locale) domModifiers(s *SpecSchedule) []string {
+	var list []string
+	for n := uint(0); n <= dom.max-dom.min; n++ {
+		switch {
+		case s.LastDom&(1<<n) == 0:
+		case n == 0:
+			list = append(list, l.Last)
+		default:
+			list = append(list, fmt.Sprintf(l.LastOffset, n))
+		}
+	}
+	if s.LastWeekdayDom {
+		list = append(list, l.LastWeekday)
+	}
+	for n := dom.min; n <= dom.max; n++ {
+		if s.WeekdayDom&(1<<n) > 0 {
+			list = append(list, fmt.Sprintf(l.Weekday, n))
+		}
+	}
+	return list
+}
+
+// dowModifiers returns the phrases for the day of week modifiers of s.
+func (l *Locale) dowModifiers(s *SpecSchedule) []string {
+	var list []string
+	for d := dow.min; d <= dow.max; d++ {
+		if s.LastDow&(1<<d) > 0 {
+			list = append(list, fmt.Sprintf(l.LastDow, l.Weekdays[d]))
+		}
+	}
+	for d := dow.min; d <= dow.max; d++ {
+		for n := uint(1); n <= 5; n++ {
+			if s.NthDow&(1<<(d*8+n)) > 0 {
+				list = append(list, fmt.Sprintf(l.NthDow, l.Ordinals[n-1], l.Weekdays[d]))
+			}
+		}
+	}
+	return list
+}
+
+// field returns the phrase for the values of a field, naming the values, and
+// the ends of ranges, with the given functions.
+func (l *Locale) field(phrases FieldPhrases, values []uint, r bounds, name, end func(uint) string) string {
+	if len(values) == 0 {
+		return ""
+	}
+	shape, step := shapeOf(values, r)
+	switch {
+	case shape == shapeEvery:
+		return phrases.Every
+	case shape == shapeOne && phrases.One != "":
+		return fmt.Sprintf(phrases.One, name(values[0]))
+	case shape == shapeOne:
+		return fmt.Sprintf(phrases.Range, name(values[0]), end(values[0]))
+	case shape == shapeStep && values[0] == r.min:
+		return fmt.Sprintf(phrases.Step, step)
+	case shape == shapeStep:
+		return fmt.Sprintf(phrases.StepFrom, step, name(values[0]))
+	case shape == shapeRange:
+		return fmt.Sprintf(phrases.Range, name(values[0]), end(values[len(values)-1]))
+	}
+	names := make([]string, len(values))
+	for i, v := range values {
+		names[i] = name(v)
+	}
+	return fmt.Sprintf(phrases.List, l.list(names))
+}
+
+// list joins items with the separators of the locale.
+func (l *Locale) list(items []string) string {
+	if len(items) < 2 {
+		return strings.Join(items, "")
+	}
+	return strings.Join(items[:len(items)-1], l.ListSep) + l.ListLast + items[len(items)-1]
+}
+
+// The shapes of the values of a field.
+const (
+	shapeEvery = iota // every value of the field
+	shapeOne          // a single value
+	shapeStep         // every nth value up to the end of the field
+	shapeRange        // consecutive values
+	shapeList         // anything else
+)
+
+// shapeOf returns the shape of the values of a field, sorted ascending, and
+// their step for shapeStep.
+func shapeOf(values []uint, r bounds) (shape int, step uint) {
+	switch {
+	case uint(len(values)) == r.max-r.min+1:
+		return shapeEvery, 1
+	case len(values) == 1:
+		return shapeOne, 0
+	case len(values) < 2:
+		return shapeList, 0
+	}
+	step = values[1] - values[0]
+	for i := 2; i < len(values); i++ {
+		if values[i]-values[i-1] != step {
+			return shapeList, 0
+		}
+	}
+	switch {
+	case step == 1:
+		return shapeRange, 1
+	case len(values) >= 3 && values[len(values)-1]+step > r.max:
+		return shapeStep, step
+	}
+	return shapeList, 0
+}
+
+// fieldValues returns the values set in the bits of a field, ascending.
+func fieldValues(bits uint64, r bounds) []uint {
+	var values []uint
+	for v := r.min; v <= r.max; v++ {
+		if bits&(1<<v) > 0 {
+			values = append(values, v)
+		}
+	}
+	return values
+}
+
+// shortDuration formats d without trailing zero units, e.g. "1h30m".
+func shortDuration(d time.Duration) string {
+	s := d.String()
+	if strings.HasSuffix(s, "m0s") {
+		s = s[:len(s)-2]
+	}
+	if strings.HasSuffix(s, "h0m") {
+		s = s[:len(s)-2]
+	}
+	return s
+}
+
+// capitalize returns s with its first letter in upper case.
+func capitalize(s string) string {
+	if s == "" {
+		return s
+	}
+	r, size := utf8.DecodeRuneInString(s)
+	return string(unicode.ToUpper(r)) + s[size:]
+}
+
+// nonEmpty returns the non-empty strings of list.
+func nonEmpty(list ...string) []string {
+	var result []string
+	for _, s := range list {
+		if s != "" {
+			result = append(result, s)
+		}
+	}
+	return result
+}
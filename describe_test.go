@@ -0,0 +1,91 @@
+package cron
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDescribe(t *testing.T) {
+	parser := NewParser(Second | Minute | Hour | Dom | Month | DowOptional | Descriptor | DayModifiers | YearOptional)
+	tests := []struct {
+		spec, en, zh string
+	}{
+		{"30 */15 9-17 * * MON-FRI",
+			"At 30 seconds past the minute, every 15 minutes, between 09:00 and 17:59, Monday through Friday",
+			"周一至周五 09:00 至 17:59 之间，每 15 分钟的第 30 秒"},
+		{"* * * * * ?", "Every second", "每秒"},
+		{"0 * * * * ?", "Every minute", "每分钟"},
+		{"*/10 * * * * ?", "Every 10 seconds", "每 10 秒"},
+		{"0 30 9 * * ?", "At 09:30", "09:30"},
+		{"15 30 9,18 * * ?", "At 09:30:15 and 18:30:15", "09:30:15和18:30:15"},
+		{"0 5/15 * * * ?", "Every 15 minutes, starting at 5 minutes past the hour", "从第 5 分钟起每 15 分钟"},
+		{"0 0 */2 * * ?", "At 0 minutes past the hour, every 2 hours", "每 2 小时，第 0 分钟"},
+		{"0 0 0 1,15 * ?", "At 00:00, on day 1 and 15 of the month", "每月 1和15 日 00:00"},
+		{"0 0 0 ? JAN-MAR SAT,SUN", "At 00:00, only on Sunday and Saturday, January through March", "1月至3月 周日和周六 00:00"},
+		{"0 0 0 1 * MON", "At 00:00, on day 1 of the month or only on Monday", "每月 1 日或周一 00:00"},
+		{"0 0 0 */2 * ?", "At 00:00, every 2 days", "每 2 天 00:00"},
+		{"0 0 0 * * */2", "At 00:00, every 2 days of the week", "每周每隔 2 天 00:00"},
+		{"0 0 0 */2 * MON", "At 00:00, every 2 days and only on Monday", "每 2 天且周一 00:00"},
+		{"0 0 12 L,15W * ?", "At 12:00, on the last day of the month or on the weekday nearest day 15 of the month",
+			"每月最后一天或每月离 15 日最近的工作日 12:00"},
+		{"0 0 12 ? * FRI#2", "At 12:00, on the second Friday of the month", "每月第二个周五 12:00"},
+		{"0 0 12 * * ? 2030", "At 12:00, only in 2030", "2030 年 12:00"},
+		{"@daily", "At 00:00", "00:00"},
+		{"@weekly", "At 00:00, only on Sunday", "周日 00:00"},
+		{"@yearly", "At 00:00, on day 1 of the month, only in January", "1月 每月 1 日 00:00"},
+		{"@every 1h30m", "Every 1h30m", "每 1h30m"},
+		{"CRON_TZ=Asia/Shanghai 0 30 9 * * ?", "At 09:30 (Asia/Shanghai time)", "09:30（Asia/Shanghai 时区）"},
+	}
+
+	for _, c := range tests {
+		sched, err := parser.Parse(c.spec)
+		if err != nil {
+			t.Errorf("%s => unexpected error %v", c.spec, err)
+			continue
+		}
+		if actual := Describe(sched, "en"); actual != c.en {
+			t.Errorf("%s => expected %q, got %q", c.spec, c.en, actual)
+		}
+		if actual := Describe(sched, "zh-CN"); actual != c.zh {
+			t.Errorf("%s => expected %q, got %q", c.spec, c.zh, actual)
+		}
+	}
+}
+
+func TestDescribeNever(t *testing.T) {
+	tests := []*SpecSchedule{
+		{},
+		{Second: 1, Hour: 1, Dom: 1 << 1, Month: 1 << 1, Dow: 1},
+		{Second: 1, Minute: 1, Hour: 1, Month: 1 << 1},
+	}
+	for i, sched := range tests {
+		if actual := Describe(sched, "en"); actual != "Never" {
+			t.Errorf("%d: expected %q, got %q", i, "Never", actual)
+		}
+		if actual := Describe(sched, "zh"); actual != "从不触发" {
+			t.Errorf("%d: expected %q, got %q", i, "从不触发", actual)
+		}
+	}
+
+	lastWeekday := &SpecSchedule{Second: 1, Minute: 1, Hour: 1, Month: 1 << 1, LastWeekdayDom: true}
+	if actual := Describe(lastWeekday, "en"); actual != "At 00:00, on the last weekday of the month, only in January" {
+		t.Errorf("unexpected description %q", actual)
+	}
+}
+
+func TestDescribeLocale(t *testing.T) {
+	sched, _ := Parse("0 30 9 * * ?")
+	if actual := Describe(sched, "xx"); actual != "At 09:30" {
+		t.Errorf("expected English for an unknown language, got %q", actual)
+	}
+
+	pirate := *English
+	pirate.At = "arr, at %s"
+	RegisterLocale("en-PIRATE", &pirate)
+	if actual := Describe(sched, "en-pirate"); actual != "Arr, at 09:30" {
+		t.Errorf("expected the registered locale, got %q", actual)
+	}
+	if actual := Describe(Every(time.Minute), "en-pirate"); actual != "Every 1m" {
+		t.Errorf("unexpected description %q", actual)
+	}
+}
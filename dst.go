@@ -0,0 +1,137 @@
+package cron
+
+import "time"
+
+// GapPolicy decides what a SpecSchedule does with activations that fall in a
+// daylight saving gap, when clocks are turned forward and a range of wall
+// clock times never occurs (e.g. 02:00-03:00 in New York every March).
+type GapPolicy int
+
+const (
+	// GapSkip drops activations inside the gap. This is the default.
+	GapSkip GapPolicy = iota
+
+	// GapNextValid runs activations inside the gap once, at the first instant
+	// after it (e.g. 03:00).
+	GapNextValid
+)
+
+// FoldPolicy decides what a SpecSchedule does with activations whose wall
+// clock time occurs twice, when clocks are turned back (e.g. 01:00-02:00 in
+// New York every November).
+type FoldPolicy int
+
+const (
+	// FoldBoth runs at both occurrences of an ambiguous time. This is the
+	// default.
+	FoldBoth FoldPolicy = iota
+
+	// FoldFirst runs only at the first occurrence, before clocks are turned
+	// back.
+	FoldFirst
+
+	// FoldSecond runs only at the second occurrence, after clocks are turned
+	// back.
+	FoldSecond
+)
+
+// String returns the name of the policy.
+func (p GapPolicy) String() string {
+	switch p {
+	case GapSkip:
+		return "skip"
+	case GapNextValid:
+		return "next-valid"
+	default:
+		return "unknown"
+	}
+}
+
+// String returns the name of the policy.
+func (p FoldPolicy) String() string {
+	switch p {
+	case FoldBoth:
+		return "both"
+	case FoldFirst:
+		return "first"
+	case FoldSecond:
+		return "second"
+	default:
+		return "unknown"
+	}
+}
+
+// nextDST returns the next activation time in the time zone of t, applying the
+// daylight saving policies of the schedule.
+func (s *SpecSchedule) nextDST(t time.Time) time.Time {
+	next := s.next(t)
+	for s.Fold != FoldBoth && !next.IsZero() {
+		ambiguous, first := fold(next)
+		if !ambiguous || first == (s.Fold == FoldFirst) {
+			break
+		}
+		next = s.next(next)
+	}
+
+	if s.Gap == GapNextValid {
+		limit := next
+		if limit.IsZero() {
+			year := t.Year() + 5
+			if !s.Year.IsZero() {
+				year = s.Year.last()
+			}
+			limit = time.Date(year+1, time.January, 1, 0, 0, 0, 0, t.Location())
+		}
+		if gap := s.gapEnd(t, limit); !gap.IsZero() {
+			return gap
+		}
+	}
+	return next
+}
+
+// gapEnd returns the end of the first daylight saving gap after t, and no
+// later than limit, that the schedule would have activated in. It returns the
+// zero time if there is none.
+func (s *SpecSchedule) gapEnd(t, limit time.Time) time.Time {
+	for _, b := t.ZoneBounds(); !b.IsZero() && !b.After(limit); _, b = b.ZoneBounds() {
+		_, before := b.Add(-time.Second).Zone()
+		_, after := b.Zone()
+		if after <= before {
+			continue
+		}
+
+		// The wall clock times in [start, end) were skipped. Look for an
+		// activation among them with the zone-less wall clock.
+		end := wall(b)
+		start := end.Add(-time.Duration(after-before) * time.Second)
+		if next := s.next(start.Add(-time.Second)); !next.IsZero() && next.Before(end) {
+			return b
+		}
+	}
+	return time.Time{}
+}
+
+// fold reports whether the wall clock time of t also occurs at another instant
+// because clocks were turned back, and if so whether t is the first of the two.
+func fold(t time.Time) (ambiguous, first bool) {
+	_, offset := t.Zone()
+	start, end := t.ZoneBounds()
+	if !start.IsZero() {
+		_, prev := start.Add(-time.Second).Zone()
+		if prev > offset && t.Before(start.Add(time.Duration(prev-offset)*time.Second)) {
+			return true, false
+		}
+	}
+	if !end.IsZero() {
+		_, next := end.Zone()
+		if next < offset && !t.Before(end.Add(-time.Duration(offset-next)*time.Second)) {
+			return true, true
+		}
+	}
+	return false, false
+}
+
+// wall returns the wall clock time of t in UTC, which has no transitions.
+func wall(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), time.UTC)
+}
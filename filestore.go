@@ -0,0 +1,208 @@
+package cron
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sync"
+)
+
+// FileStore is a Store keeping states in a local file, as an append-only log
+// of JSON records. Every change is synced to disk before returning. The log
+// is compacted, by atomically replacing the file, when opened and whenever it
+// holds many more records than states.
+type FileStore struct {
+	mux     sync.Mutex
+	path    string
+	file    *os.File
+	states  map[string]EntryState
+	records int
+}
+
+// fileRecord is a line of the FileStore log.
+type fileRecord struct {
+	State   *EntryState `json:"state,omitempty"`
+	Deleted string      `json:"deleted,omitempty"`
+}
+
+// NewFileStore opens the FileStore at path, creating the file if needed.
+func NewFileStore(path string) (*FileStore, error) {
+	s := &FileStore{
+		path:   path,
+		states: make(map[string]EntryState),
+	}
+	if err := s.replay(); err != nil {
+		return nil, err
+	}
+	if err := s.compact(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Load returns the states stored, sorted by name.
+func (s *FileStore) Load() ([]EntryState, error) {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	return sortedStates(s.states), nil
+}
+
+// Save stores the state of an entry.
+func (s *FileStore) Save(state EntryState) error {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	if err := s.append(fileRecord{State: &state}); err != nil {
+		return err
+	}
+	s.states[state.Name] = state
+	return s.maybeCompact()
+}
+
+// Delete removes the state of the named entry.
+func (s *FileStore) Delete(name string) error {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	if _, ok := s.states[name]; !ok {
+		return nil
+	}
+	if err := s.append(fileRecord{Deleted: name}); err != nil {
+		return err
+	}
+	delete(s.states, name)
+	return s.maybeCompact()
+}
+
+// Close closes the file of the store.
+func (s *FileStore) Close() error {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	return s.file.Close()
+}
+
+// replay reads the log into states. A truncated last record, left by a crash
+// while appending, is ignored; any other undecodable record is an error.
+func (s *FileStore) replay() error {
+	file, err := os.Open(s.path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(nil, 1<<20)
+	var (
+		line int
+		torn error
+	)
+	for scanner.Scan() {
+		line++
+		if torn != nil {
+			// The undecodable record was not the last one.
+			return fmt.Errorf("cron: corrupt record at line %d of store %s: %s", line-1, s.path, torn)
+		}
+		var record fileRecord
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			torn = err
+			continue
+		}
+		switch {
+		case record.State != nil:
+			s.states[record.State.Name] = *record.State
+		case record.Deleted != "":
+			delete(s.states, record.Deleted)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("cron: failed to read store %s: %s", s.path, err)
+	}
+	return nil
+}
+
+func (s *FileStore) append(record fileRecord) error {
+	line, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	if _, err := s.file.Write(append(line, '\n')); err != nil {
+		return err
+	}
+	s.records++
+	return s.file.Sync()
+}
+
+// syncDir flushes the entries of the directory to disk. Windows cannot sync
+// a directory, and commits renames on its own.
+func syncDir(dir string) error {
+	if runtime.GOOS == "windows" {
+		return nil
+	}
+	d, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	defer d.Close()
+	return d.Sync()
+}
+
+func (s *FileStore) maybeCompact() error {
+	if s.records <= 2*len(s.states)+16 {
+		return nil
+	}
+	return s.compact()
+}
+
+// compact writes the current states to a temporary file, then renames it over
+// the log, so that the log is replaced atomically. The directory is synced
+// too, so that the rename survives a crash.
+func (s *FileStore) compact() error {
+	tmp, err := os.CreateTemp(filepath.Dir(s.path), filepath.Base(s.path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	writer := bufio.NewWriter(tmp)
+	for _, state := range sortedStates(s.states) {
+		state := state
+		line, err := json.Marshal(fileRecord{State: &state})
+		if err != nil {
+			tmp.Close()
+			return err
+		}
+		writer.Write(append(line, '\n'))
+	}
+	if err := writer.Flush(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp.Name(), s.path); err != nil {
+		return err
+	}
+	if err := syncDir(filepath.Dir(s.path)); err != nil {
+		return err
+	}
+
+	file, err := os.OpenFile(s.path, os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+	if s.file != nil {
+		s.file.Close()
+	}
+	s.file = file
+	s.records = len(s.states)
+	return nil
+}
@@ -0,0 +1,184 @@
+package cron
+
+import (
+	"fmt"
+	"strings"
+)
+
+// String returns the spec of the schedule in a canonical form, with a
+// seconds field and a "?" day of week when any day matches, e.g.
+// "0 */15 9-17 * * 1-5". Parsing it yields an equal schedule, given a parser
+// with the DayModifiers and YearOptional options for schedules which use day
+// modifiers or years. The daylight saving policies Gap and Fold are not part
+// of the spec.
+func (s *SpecSchedule) String() string {
+	fields := []string{
+		formatField(s.Second, seconds),
+		formatField(s.Minute, minutes),
+		formatField(s.Hour, hours),
+		formatDom(s),
+		formatField(s.Month, months),
+		formatDow(s),
+	}
+	if !s.Year.IsZero() {
+		var values []uint
+		for year := years.min; year <= years.max; year++ {
+			if s.Year.Contains(int(year)) {
+				values = append(values, year)
+			}
+		}
+		fields = append(fields, formatValues(values, years, false))
+	}
+
+	spec := strings.Join(fields, " ")
+	if s.Location != nil {
+		spec = "CRON_TZ=" + s.Location.String() + " " + spec
+	}
+	return spec
+}
+
+// String returns the spec of the schedule, e.g. "@every 1h30m0s".
+func (schedule ConstantDelaySchedule) String() string {
+	return "@every " + schedule.Delay.String()
+}
+
+// formatField returns the expression for the bits of a field, the inverse of
+// getField.
+func formatField(bits uint64, r bounds) string {
+	var values []uint
+	for v := r.min; v <= r.max; v++ {
+		if bits&(1<<v) > 0 {
+			values = append(values, v)
+		}
+	}
+	if bits&starBit == 0 || bits&(1<<r.min) == 0 {
+		return formatValues(values, r, true)
+	}
+
+	// A star always includes the minimum. Take the step covering the most
+	// values, and list the rest.
+	if uint(len(values)) == r.max-r.min+1 {
+		return "*"
+	}
+	for step := uint(2); ; step++ {
+		var rest []uint
+		covered := true
+		for v := r.min; v <= r.max; v += step {
+			if bits&(1<<v) == 0 {
+				covered = false
+				break
+			}
+		}
+		if !covered {
+			continue
+		}
+		for _, v := range values {
+			if (v-r.min)%step != 0 {
+				rest = append(rest, v)
+			}
+		}
+		if len(rest) == 0 {
+			return fmt.Sprintf("*/%d", step)
+		}
+		return fmt.Sprintf("*/%d,%s", step, formatValues(rest, r, true))
+	}
+}
+
+// formatValues returns the shortest of a step range, such as "5/15" or
+// "5-35/15", and a list of ranges for the given values, sorted ascending.
+// With wrap, runs at both ends of the bounds are joined, such as "22-2".
+func formatValues(values []uint, r bounds, wrap bool) string {
+	if len(values) >= 3 {
+		step := values[1] - values[0]
+		for i := 2; i < len(values) && step > 1; i++ {
+			if values[i]-values[i-1] != step {
+				step = 0
+			}
+		}
+		first, last := values[0], values[len(values)-1]
+		switch {
+		case step > 1 && last+step > r.max:
+			return fmt.Sprintf("%d/%d", first, step)
+		case step > 1:
+			return fmt.Sprintf("%d-%d/%d", first, last, step)
+		}
+	}
+
+	// Split the values into runs of consecutive values.
+	var runs [][2]uint
+	for _, v := range values {
+		if n := len(runs); n > 0 && runs[n-1][1]+1 == v {
+			runs[n-1][1] = v
+		} else {
+			runs = append(runs, [2]uint{v, v})
+		}
+	}
+	if n := len(runs); wrap && n > 1 && runs[0][0] == r.min && runs[n-1][1] == r.max &&
+		runs[0][1]-runs[0][0]+runs[n-1][1]-runs[n-1][0] >= 1 {
+		runs[n-1][1] = runs[0][1]
+		runs = runs[1:]
+	}
+
+	list := make([]string, 0, len(runs))
+	for _, run := range runs {
+		switch {
+		case run[0] == run[1]:
+			list = append(list, fmt.Sprint(run[0]))
+		case run[0]+1 == run[1]:
+			list = append(list, fmt.Sprint(run[0]), fmt.Sprint(run[1]))
+		default:
+			list = append(list, fmt.Sprintf("%d-%d", run[0], run[1]))
+		}
+	}
+	return strings.Join(list, ",")
+}
+
+// formatDom returns the day of month field of s, with its modifiers.
+func formatDom(s *SpecSchedule) string {
+	var list []string
+	if s.Dom != 0 {
+		list = append(list, formatField(s.Dom, dom))
+	}
+	for n := uint(0); n <= dom.max-dom.min; n++ {
+		switch {
+		case s.LastDom&(1<<n) == 0:
+		case n == 0:
+			list = append(list, "L")
+		default:
+			list = append(list, fmt.Sprintf("L-%d", n))
+		}
+	}
+	if s.LastWeekdayDom {
+		list = append(list, "LW")
+	}
+	for n := dom.min; n <= dom.max; n++ {
+		if s.WeekdayDom&(1<<n) > 0 {
+			list = append(list, fmt.Sprintf("%dW", n))
+		}
+	}
+	return strings.Join(list, ",")
+}
+
+// formatDow returns the day of week field of s, with its modifiers.
+func formatDow(s *SpecSchedule) string {
+	var list []string
+	switch {
+	case s.Dow == all(dow):
+		list = append(list, "?")
+	case s.Dow != 0:
+		list = append(list, formatField(s.Dow, dow))
+	}
+	for d := dow.min; d <= dow.max; d++ {
+		if s.LastDow&(1<<d) > 0 {
+			list = append(list, fmt.Sprintf("%dL", d))
+		}
+	}
+	for d := dow.min; d <= dow.max; d++ {
+		for n := uint(1); n <= 5; n++ {
+			if s.NthDow&(1<<(d*8+n)) > 0 {
+				list = append(list, fmt.Sprintf("%d#%d", d, n))
+			}
+		}
+	}
+	return strings.Join(list, ",")
+}
@@ -0,0 +1,127 @@
+package cron
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestSpecScheduleString(t *testing.T) {
+	parser := NewParser(Second | Minute | Hour | Dom | Month | DowOptional | Descriptor | DayModifiers | YearOptional)
+	tests := []struct {
+		spec, expected string
+	}{
+		{"* * * * * *", "* * * * * ?"},
+		{"0 0 0 * * ?", "0 0 0 * * ?"},
+		{"@daily", "0 0 0 * * ?"},
+		{"@weekly", "0 0 0 * * 0"},
+		{"@monthly", "0 0 0 1 * ?"},
+		{"0 */15 * * * *", "0 */15 * * * ?"},
+		{"0 0/15 * * * *", "0 0/15 * * * ?"},
+		{"0 5-50/15 * * * *", "0 5/15 * * * ?"},
+		{"0 5-40/15 * * * *", "0 5-35/15 * * * ?"},
+		{"0 0-59 * * * *", "0 0-59 * * * ?"},
+		{"0 */15,7 * * * *", "0 */15,7 * * * ?"},
+		{"0 0 9-17 * * MON-FRI", "0 0 9-17 * * 1-5"},
+		{"0 0 9,10,12,13,14 * * ?", "0 0 9,10,12-14 * * ?"},
+		{"0 0 22-2 * * ?", "0 0 22-2 * * ?"},
+		{"0 0 0 ? NOV-FEB SAT,SUN", "0 0 0 * 11-2 0,6"},
+		{"0 0 0 L,L-3,LW,15W * ?", "0 0 0 L,L-3,LW,15W * ?"},
+		{"0 0 0 ? * FRI#2,5L", "0 0 0 * * 5L,5#2"},
+		{"0 0 0 1 1 ? 2030,2040-2045", "0 0 0 1 1 ? 2030,2040-2045"},
+		{"CRON_TZ=Asia/Shanghai 0 30 9 * * ?", "CRON_TZ=Asia/Shanghai 0 30 9 * * ?"},
+	}
+
+	for _, c := range tests {
+		sched, err := parser.Parse(c.spec)
+		if err != nil {
+			t.Errorf("%s => unexpected error %v", c.spec, err)
+			continue
+		}
+		actual := sched.(*SpecSchedule).String()
+		if actual != c.expected {
+			t.Errorf("%s => expected %q, got %q", c.spec, c.expected, actual)
+		}
+		again, err := parser.Parse(actual)
+		if err != nil {
+			t.Errorf("%s => unexpected error %v parsing %q", c.spec, err, actual)
+			continue
+		}
+		if !sameSchedule(sched.(*SpecSchedule), again.(*SpecSchedule)) {
+			t.Errorf("%s => %q parses to %+v, expected %+v", c.spec, actual, again, sched)
+		}
+	}
+}
+
+// Test that every value of every field survives a round trip, with and
+// without the star bit.
+func TestSpecScheduleStringRoundTrip(t *testing.T) {
+	fields := []struct {
+		r   bounds
+		set func(s *SpecSchedule, bits uint64)
+	}{
+		{seconds, func(s *SpecSchedule, bits uint64) { s.Second = bits }},
+		{minutes, func(s *SpecSchedule, bits uint64) { s.Minute = bits }},
+		{hours, func(s *SpecSchedule, bits uint64) { s.Hour = bits }},
+		{dom, func(s *SpecSchedule, bits uint64) { s.Dom = bits }},
+		{months, func(s *SpecSchedule, bits uint64) { s.Month = bits }},
+		{dow, func(s *SpecSchedule, bits uint64) { s.Dow = bits }},
+	}
+	masks := []uint64{
+		0x5555555555555555, 0x3333333333333333, 0x0f0f0f0f0f0f0f0f,
+		0x8000000000000001, 0x00ff00ff00ff00ff, 0x1248124812481248,
+		0xffffffffffffffff, 0x9249249249249249, 0x0000000000000ff0,
+	}
+
+	for _, f := range fields {
+		for _, mask := range masks {
+			for _, star := range []bool{false, true} {
+				bits := mask & getBits(f.r.min, f.r.max, 1)
+				if star {
+					bits |= starBit | 1<<f.r.min
+				}
+				if bits == 0 {
+					continue
+				}
+				sched, _ := Parse("* * * * * ?")
+				f.set(sched.(*SpecSchedule), bits)
+				spec := sched.(*SpecSchedule).String()
+				again, err := Parse(spec)
+				if err != nil {
+					t.Errorf("%b => unexpected error %v parsing %q", bits, err, spec)
+					continue
+				}
+				if !reflect.DeepEqual(sched, again) {
+					t.Errorf("%b => %q parses to %+v, expected %+v", bits, spec, again, sched)
+				}
+			}
+		}
+	}
+}
+
+func TestConstantDelayScheduleString(t *testing.T) {
+	for _, delay := range []time.Duration{time.Second, 90 * time.Minute, 36*time.Hour + 5*time.Second} {
+		sched := Every(delay)
+		again, err := Parse(sched.String())
+		if err != nil {
+			t.Errorf("%v => unexpected error %v", delay, err)
+			continue
+		}
+		if again != sched {
+			t.Errorf("%v => %q parses to %v", delay, sched.String(), again)
+		}
+	}
+	if s := Every(90 * time.Minute).String(); s != "@every 1h30m0s" {
+		t.Errorf("unexpected spec %q", s)
+	}
+}
+
+// sameSchedule compares schedules, with locations by name.
+func sameSchedule(a, b *SpecSchedule) bool {
+	x, y := *a, *b
+	if !sameLocation(x.Location, y.Location) {
+		return false
+	}
+	x.Location, y.Location = nil, nil
+	return reflect.DeepEqual(x, y)
+}
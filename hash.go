@@ -0,0 +1,113 @@
+package cron
+
+import (
+	"fmt"
+	"hash/fnv"
+	"strings"
+)
+
+// Jenkins-style hash tokens spread schedules with the same spec across the
+// range of a field. The value is derived from the name given to ParseWithName,
+// so it differs between entries but stays the same across restarts:
+//
+//   H           a value in the range of the field, e.g. "H * * * * ?"
+//   H(a-b)      a value in [a, b], e.g. "0 H(0-29) * * * ?"
+//   H/n         every n, starting at a value in [0, n), e.g. "0 H/15 * * * ?"
+//   H(a-b)/n    every n within [a, b], starting at a value in [a, a+n)
+//
+// In the day of month field, H without a range is limited to 1-28 so that it
+// activates in every month.
+
+// expandHash replaces the hash tokens in field with plain values and ranges,
+// using the name and position of the field as a seed.
+func expandHash(field string, r bounds, name string, position int) (string, error) {
+	if !strings.Contains(field, "H") {
+		return field, nil
+	}
+
+	h := fnv.New64a()
+	h.Write([]byte(name))
+	h.Write([]byte{byte(position)})
+	seed := mix(h.Sum64())
+
+	exprs := strings.Split(field, ",")
+	for i, expr := range exprs {
+		if !strings.HasPrefix(expr, "H") {
+			continue
+		}
+		var err error
+		if exprs[i], err = expandHashRange(expr, r, seed); err != nil {
+			return "", err
+		}
+	}
+	return strings.Join(exprs, ","), nil
+}
+
+// expandHashRange returns the plain range for a single hash token.
+func expandHashRange(expr string, r bounds, seed uint64) (string, error) {
+	var (
+		rangeAndStep = strings.Split(expr, "/")
+		low, high    = r.min, r.max
+	)
+	if r.min == dom.min && r.max == dom.max {
+		high = 28
+	}
+	if len(rangeAndStep) > 2 {
+		return "", parseErrorf(ParseErrorSyntax, expr, "Too many slashes: %s", expr)
+	}
+
+	switch token := rangeAndStep[0]; {
+	case token == "H":
+	case strings.HasPrefix(token, "H(") && strings.HasSuffix(token, ")"):
+		lowAndHigh := strings.Split(token[2:len(token)-1], "-")
+		if len(lowAndHigh) != 2 {
+			return "", parseErrorf(ParseErrorSyntax, expr, "Hash range must be H(a-b): %s", expr)
+		}
+		var err error
+		if low, err = parseIntOrName(lowAndHigh[0], r.names); err != nil {
+			return "", err
+		}
+		if high, err = parseIntOrName(lowAndHigh[1], r.names); err != nil {
+			return "", err
+		}
+		if low < r.min {
+			return "", parseErrorf(ParseErrorRange, expr, "Beginning of range (%d) below minimum (%d): %s", low, r.min, expr)
+		}
+		if high > r.max {
+			return "", parseErrorf(ParseErrorRange, expr, "End of range (%d) above maximum (%d): %s", high, r.max, expr)
+		}
+		if low > high {
+			return "", parseErrorf(ParseErrorRange, expr, "Beginning of range (%d) beyond end of range (%d): %s", low, high, expr)
+		}
+	default:
+		return "", parseErrorf(ParseErrorSyntax, expr, "Failed to parse hash token: %s", expr)
+	}
+
+	if len(rangeAndStep) == 1 {
+		return fmt.Sprint(low + uint(seed%uint64(high-low+1))), nil
+	}
+
+	step, err := mustParseInt(rangeAndStep[1])
+	if err != nil {
+		return "", err
+	}
+	if step == 0 {
+		return "", parseErrorf(ParseErrorStep, expr, "Step of range should be a positive number: %s", expr)
+	}
+	start := low + uint(seed%uint64(step))
+	if start > high {
+		start = low + uint(seed%uint64(high-low+1))
+	}
+	return fmt.Sprintf("%d-%d/%d", start, high, step), nil
+}
+
+// mix scrambles the bits of a FNV hash, whose low bits vary little between
+// similar names such as "job-1" and "job-2".
+func mix(x uint64) uint64 {
+	x ^= x >> 33
+	x *= 0xff51afd7ed558ccd
+	x ^= x >> 33
+	x *= 0xc4ceb9fe1a85ec53
+	x ^= x >> 33
+	return x
+}
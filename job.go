@@ -1,14 +1,21 @@
 package cron
 
+import "context"
+
 //
 // Author: 陈永佳 chenyongjia@parkingwang.com, yoojiachen@gmail.com
 // Job defines of cron
 //
 
-// Context of job
+// Context of job. The embedded Context is cancelled when the Cron is stopped,
+// when the entry is removed, or when the entry's Timeout expires, so that
+// long-running jobs can return early.
 type JobContext struct {
+	context.Context
 	Name  string
 	Count int
+	// Attempt of the run, starting at 1 and incremented on every retry.
+	Attempt int
 }
 
 // Job is an interface for submitted cron jobs.
@@ -21,6 +28,12 @@ type Job2 interface {
 	Run(ctx *JobContext)
 }
 
+// Job3 is an interface for submitted cron jobs which report failure. Failed
+// runs are retried according to the entry's RetryPolicy.
+type Job3 interface {
+	Run(ctx *JobContext) error
+}
+
 // A wrapper that turns a func() into a cron.Job
 type JobWrapper func()
 
@@ -37,3 +50,22 @@ type Job2To1 struct {
 }
 
 func (fun Job2To1) Run(ctx *JobContext) { fun.job.Run() }
+
+// A wrapper that turns a func(ctx *JobContext) error into a cron.Job3
+type Job3Wrapper func(ctx *JobContext) error
+
+func (fun Job3Wrapper) Run(ctx *JobContext) error { return fun(ctx) }
+
+// A wrapper that turns a cron.Job3 into a cron.Job2. The scheduler unwraps it
+// to observe the returned error.
+type Job3To2 struct {
+	job Job3
+}
+
+// NewJob3To2 wraps a Job3 for a JobEntry, so that options such as Retry can
+// be set through AddEntry.
+func NewJob3To2(job Job3) *Job3To2 {
+	return &Job3To2{job: job}
+}
+
+func (fun Job3To2) Run(ctx *JobContext) { fun.job.Run(ctx) }
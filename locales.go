@@ -0,0 +1,158 @@
+package cron
+
+import "strings"
+
+// English describes schedules such as "At 30 seconds past the minute, every
+// 15 minutes, between 09:00 and 17:59, Monday through Friday".
+var English = &Locale{
+	Months: [12]string{"January", "February", "March", "April", "May", "June",
+		"July", "August", "September", "October", "November", "December"},
+	Weekdays: [7]string{"Sunday", "Monday", "Tuesday", "Wednesday", "Thursday", "Friday", "Saturday"},
+	Ordinals: [5]string{"first", "second", "third", "fourth", "fifth"},
+	ListSep:  ", ",
+	ListLast: " and ",
+	Or:       " or ",
+	And:      " and ",
+	Second: FieldPhrases{
+		Every:    "every second",
+		One:      "at %s seconds past the minute",
+		Step:     "every %d seconds",
+		StepFrom: "every %d seconds, starting at %s seconds past the minute",
+		Range:    "seconds %s through %s past the minute",
+		List:     "at %s seconds past the minute",
+	},
+	Minute: FieldPhrases{
+		Every:    "every minute",
+		One:      "at %s minutes past the hour",
+		Step:     "every %d minutes",
+		StepFrom: "every %d minutes, starting at %s minutes past the hour",
+		Range:    "minutes %s through %s past the hour",
+		List:     "at %s minutes past the hour",
+	},
+	Hour: FieldPhrases{
+		Step:     "every %d hours",
+		StepFrom: "every %d hours, starting at %s",
+		Range:    "between %s and %s",
+		List:     "during the %s hours",
+	},
+	Dom: FieldPhrases{
+		One:      "on day %s of the month",
+		Step:     "every %d days",
+		StepFrom: "every %d days, starting on day %s of the month",
+		Range:    "between day %s and %s of the month",
+		List:     "on day %s of the month",
+	},
+	Month: FieldPhrases{
+		One:      "only in %s",
+		Step:     "every %d months",
+		StepFrom: "every %d months, starting in %s",
+		Range:    "%s through %s",
+		List:     "only in %s",
+	},
+	Dow: FieldPhrases{
+		One:      "only on %s",
+		Step:     "every %d days of the week",
+		StepFrom: "every %d days of the week, starting on %s",
+		Range:    "%s through %s",
+		List:     "only on %s",
+	},
+	Year: FieldPhrases{
+		One:      "only in %s",
+		Step:     "every %d years",
+		StepFrom: "every %d years, starting in %s",
+		Range:    "%s through %s",
+		List:     "only in %s",
+	},
+	At:          "at %s",
+	Last:        "on the last day of the month",
+	LastOffset:  "%d days before the last day of the month",
+	Weekday:     "on the weekday nearest day %d of the month",
+	LastWeekday: "on the last weekday of the month",
+	LastDow:     "on the last %s of the month",
+	NthDow:      "on the %s %s of the month",
+	Every:       "every %s",
+	Never:       "Never",
+	Zone:        "%s (%s time)",
+	Compose: func(p Phrases) string {
+		return capitalize(strings.Join(nonEmpty(p.Second, p.Minute, p.Hour, p.Day, p.Month, p.Year), ", "))
+	},
+}
+
+// Chinese describes schedules such as "周一至周五 09:00 至 17:59 之间，每 15
+// 分钟的第 30 秒".
+var Chinese = &Locale{
+	Months:   [12]string{"1月", "2月", "3月", "4月", "5月", "6月", "7月", "8月", "9月", "10月", "11月", "12月"},
+	Weekdays: [7]string{"周日", "周一", "周二", "周三", "周四", "周五", "周六"},
+	Ordinals: [5]string{"一", "二", "三", "四", "五"},
+	ListSep:  "、",
+	ListLast: "和",
+	Or:       "或",
+	And:      "且",
+	Second: FieldPhrases{
+		Every:    "每秒",
+		One:      "第 %s 秒",
+		Step:     "每 %d 秒",
+		StepFrom: "从第 %[2]s 秒起每 %[1]d 秒",
+		Range:    "第 %s 至 %s 秒",
+		List:     "第 %s 秒",
+	},
+	Minute: FieldPhrases{
+		Every:    "每分钟",
+		One:      "第 %s 分钟",
+		Step:     "每 %d 分钟",
+		StepFrom: "从第 %[2]s 分钟起每 %[1]d 分钟",
+		Range:    "第 %s 至 %s 分钟",
+		List:     "第 %s 分钟",
+	},
+	Hour: FieldPhrases{
+		Step:     "每 %d 小时",
+		StepFrom: "从 %[2]s 起每 %[1]d 小时",
+		Range:    "%s 至 %s 之间",
+		List:     "%s 这几个小时内",
+	},
+	Dom: FieldPhrases{
+		One:      "每月 %s 日",
+		Step:     "每 %d 天",
+		StepFrom: "从每月 %[2]s 日起每 %[1]d 天",
+		Range:    "每月 %s 至 %s 日",
+		List:     "每月 %s 日",
+	},
+	Month: FieldPhrases{
+		One:      "%s",
+		Step:     "每 %d 个月",
+		StepFrom: "从%[2]s起每 %[1]d 个月",
+		Range:    "%s至%s",
+		List:     "%s",
+	},
+	Dow: FieldPhrases{
+		One:      "%s",
+		Step:     "每周每隔 %d 天",
+		StepFrom: "从%[2]s起每隔 %[1]d 天",
+		Range:    "%s至%s",
+		List:     "%s",
+	},
+	Year: FieldPhrases{
+		One:      "%s 年",
+		Step:     "每 %d 年",
+		StepFrom: "从 %[2]s 年起每 %[1]d 年",
+		Range:    "%s 至 %s 年",
+		List:     "%s 年",
+	},
+	At:          "%s",
+	Last:        "每月最后一天",
+	LastOffset:  "每月最后一天前 %d 天",
+	Weekday:     "每月离 %d 日最近的工作日",
+	LastWeekday: "每月最后一个工作日",
+	LastDow:     "每月最后一个%s",
+	NthDow:      "每月第%s个%s",
+	Every:       "每 %s",
+	Never:       "从不触发",
+	Zone:        "%s（%s 时区）",
+	Compose: func(p Phrases) string {
+		var (
+			date = strings.Join(nonEmpty(p.Year, p.Month, p.Day, p.Hour), " ")
+			time = strings.Join(nonEmpty(p.Minute, p.Second), "的")
+		)
+		return strings.Join(nonEmpty(date, time), "，")
+	},
+}
@@ -0,0 +1,57 @@
+package cron
+
+import (
+	"sync"
+	"time"
+)
+
+// Locker lets several replicas of a Cron agree on which one runs each
+// occurrence of an entry. Before running an entry the Cron claims the
+// occurrence, keyed by the entry name and the time it was scheduled at rather
+// than the time of the local clock, so that replicas with skewed clocks claim
+// the same key. With a Locker, "@every" entries run on multiples of their
+// delay since the Unix epoch rather than relative to when each replica
+// started, so that replicas schedule the same occurrences.
+type Locker interface {
+	// Lock claims the occurrence of the named entry scheduled at the given
+	// time. It returns true for the one caller which should run it.
+	Lock(name string, scheduled time.Time) (bool, error)
+}
+
+// MemoryLocker is a Locker shared by the Crons of a single process, for tests.
+type MemoryLocker struct {
+	mux     sync.Mutex
+	claimed map[string]time.Time
+}
+
+// NewMemoryLocker returns a MemoryLocker with no occurrence claimed.
+func NewMemoryLocker() *MemoryLocker {
+	return &MemoryLocker{claimed: make(map[string]time.Time)}
+}
+
+// Lock claims the occurrence, unless it or a later one of the same entry was
+// claimed already.
+func (l *MemoryLocker) Lock(name string, scheduled time.Time) (bool, error) {
+	l.mux.Lock()
+	defer l.mux.Unlock()
+	if last, ok := l.claimed[name]; ok && !last.Before(scheduled) {
+		return false, nil
+	}
+	l.claimed[name] = scheduled
+	return true, nil
+}
+
+// claim reports whether this Cron runs the occurrence of e scheduled at the
+// given time. Without Locker every occurrence is run; if the Locker fails the
+// occurrence is skipped, to rather miss a run than run it twice.
+func (c *Cron) claim(e *JobEntry, scheduled time.Time) bool {
+	if c.Locker == nil {
+		return true
+	}
+	ok, err := c.Locker.Lock(e.Name, scheduled)
+	if err != nil {
+		c.logf("cron: failed to lock %s at %s: %v", e.Name, scheduled, err)
+		return false
+	}
+	return ok
+}
@@ -0,0 +1,62 @@
+//go:build unix
+
+package cron
+
+import (
+	"bytes"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"syscall"
+	"time"
+)
+
+// FileLocker is a Locker for the Crons of a single host, keeping a lock file
+// per entry in a directory. The file is locked with flock while the last
+// occurrence claimed, recorded in it, is compared and updated.
+type FileLocker struct {
+	dir string
+}
+
+// NewFileLocker returns a FileLocker keeping its files in dir, which is
+// created if needed.
+func NewFileLocker(dir string) (*FileLocker, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	return &FileLocker{dir: dir}, nil
+}
+
+// Lock claims the occurrence, unless it or a later one of the same entry was
+// claimed already.
+func (l *FileLocker) Lock(name string, scheduled time.Time) (bool, error) {
+	path := filepath.Join(l.dir, url.PathEscape(name)+".lock")
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return false, err
+	}
+	defer file.Close()
+
+	if err := syscall.Flock(int(file.Fd()), syscall.LOCK_EX); err != nil {
+		return false, err
+	}
+	defer syscall.Flock(int(file.Fd()), syscall.LOCK_UN)
+
+	content, err := io.ReadAll(file)
+	if err != nil {
+		return false, err
+	}
+	if last, err := strconv.ParseInt(string(bytes.TrimSpace(content)), 10, 64); err == nil && last >= scheduled.UnixNano() {
+		return false, nil
+	}
+
+	if err := file.Truncate(0); err != nil {
+		return false, err
+	}
+	if _, err := file.WriteAt([]byte(strconv.FormatInt(scheduled.UnixNano(), 10)), 0); err != nil {
+		return false, err
+	}
+	return true, file.Sync()
+}
@@ -0,0 +1,13 @@
+//go:build unix
+
+package cron
+
+import "testing"
+
+func TestFileLocker(t *testing.T) {
+	locker, err := NewFileLocker(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	testLocker(t, locker)
+}
@@ -0,0 +1,146 @@
+package cron
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestMemoryLocker(t *testing.T) {
+	testLocker(t, NewMemoryLocker())
+}
+
+// testLocker checks that a Locker grants each occurrence to one replica only.
+func testLocker(t *testing.T, locker Locker) {
+	at := getTime("Mon Jul 9 15:00 2012")
+
+	// Many replicas race for the same occurrences, one wins each.
+	var (
+		wg   sync.WaitGroup
+		mux  sync.Mutex
+		wins = map[string]int{}
+	)
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for _, name := range []string{"a", "b/c"} {
+				if ok, err := locker.Lock(name, at); err != nil {
+					t.Error(err)
+				} else if ok {
+					mux.Lock()
+					wins[name]++
+					mux.Unlock()
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	if wins["a"] != 1 || wins["b/c"] != 1 {
+		t.Errorf("expected one winner per occurrence, got %v", wins)
+	}
+
+	if ok, _ := locker.Lock("a", at.Add(-time.Hour)); ok {
+		t.Error("expected earlier occurrence not claimed")
+	}
+	if ok, _ := locker.Lock("a", at.Add(time.Hour)); !ok {
+		t.Error("expected next occurrence claimed")
+	}
+}
+
+// Test that replicas sharing a Locker run each occurrence once, even though
+// their clocks are skewed.
+func TestCronLocker(t *testing.T) {
+	var (
+		locker = NewMemoryLocker()
+		mux    sync.Mutex
+		runs   []string
+		wg     sync.WaitGroup
+	)
+	wg.Add(1)
+
+	var clocks []*FakeClock
+	for i, skew := range []time.Duration{0, 2 * time.Second, -time.Second} {
+		clock := NewFakeClock(getTime("Mon Jul 9 14:45 2012").Add(skew))
+		clocks = append(clocks, clock)
+		replica := string(rune('a' + i))
+
+		cron := NewWithClock(time.UTC, clock)
+		cron.Locker = locker
+		cron.AddFunc2("0 0 * * * ?", func(ctx *JobContext) {
+			mux.Lock()
+			runs = append(runs, replica)
+			mux.Unlock()
+			wg.Done()
+		}, "hourly")
+		cron.Start()
+		defer cron.Stop()
+		clock.BlockUntil(1)
+	}
+
+	for _, clock := range clocks {
+		clock.Advance(15 * time.Minute)
+		clock.BlockUntil(1)
+	}
+	wg.Wait()
+
+	mux.Lock()
+	defer mux.Unlock()
+	if len(runs) != 1 {
+		t.Errorf("expected a single run over all replicas, got %v", runs)
+	}
+}
+
+// Test that replicas started at different times agree on the occurrences of
+// an "@every" entry, and run each once.
+func TestCronLockerEvery(t *testing.T) {
+	var (
+		locker = NewMemoryLocker()
+		mux    sync.Mutex
+		runs   = map[string]int{}
+	)
+
+	var clocks []*FakeClock
+	for i, start := range []string{"Mon Jul 9 14:45:07 2012", "Mon Jul 9 14:45:23 2012"} {
+		clock := NewFakeClock(getTime(start))
+		clocks = append(clocks, clock)
+		replica := string(rune('a' + i))
+
+		cron := NewWithClock(time.UTC, clock)
+		cron.Locker = locker
+		cron.AddEntry(&JobEntry{
+			Schedule: Every(time.Minute),
+			Name:     "every",
+			Job: Job2Wrapper(func(ctx *JobContext) {
+				mux.Lock()
+				runs[replica]++
+				mux.Unlock()
+			}),
+		})
+		cron.Start()
+		defer cron.Stop()
+		clock.BlockUntil(1)
+		if next := cron.Entries()[0].NextTime; !next.Equal(getTime("Mon Jul 9 14:46 2012")) {
+			t.Errorf("%s: expected next run aligned on the minute, got %v", replica, next)
+		}
+	}
+
+	// Both replicas reach three occurrences, a little apart.
+	for i := 0; i < 3; i++ {
+		for _, clock := range clocks {
+			clock.Advance(time.Minute)
+			clock.BlockUntil(1)
+		}
+	}
+	waitUntil(t, "runs", func() bool {
+		mux.Lock()
+		defer mux.Unlock()
+		return runs["a"]+runs["b"] >= 3
+	})
+
+	mux.Lock()
+	defer mux.Unlock()
+	if runs["a"]+runs["b"] != 3 {
+		t.Errorf("expected 3 runs over both replicas, got %v", runs)
+	}
+}
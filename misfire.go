@@ -0,0 +1,121 @@
+package cron
+
+import "time"
+
+// DefaultMaxMisfires caps the runs fired by MisfireFireAll when
+// JobEntry.MaxMisfires is zero.
+const DefaultMaxMisfires = 100
+
+// MisfirePolicy decides what happens to the runs of an entry which were
+// missed, because the Cron was not running at the time or because it fired
+// later than JobEntry.MisfireThreshold (e.g. after the host was suspended).
+type MisfirePolicy int
+
+const (
+	// MisfireIgnore drops the missed runs and waits for the next one.
+	MisfireIgnore MisfirePolicy = iota
+	// MisfireFireOnce fires a single run for all the missed ones.
+	MisfireFireOnce
+	// MisfireFireAll fires a run for every missed one, at most MaxMisfires.
+	// The runs are subject to the entry's overlap policy.
+	MisfireFireAll
+)
+
+func (p MisfirePolicy) String() string {
+	switch p {
+	case MisfireIgnore:
+		return "ignore"
+	case MisfireFireOnce:
+		return "fire once"
+	case MisfireFireAll:
+		return "fire all"
+	}
+	return "unknown"
+}
+
+// catchUp fires the runs of e missed since its PrevTime, when the scheduler
+// starts or the entry is added. Entries which never ran have nothing to catch
+// up with.
+func (c *Cron) catchUp(e *JobEntry, now time.Time) {
+	if e.PrevTime.IsZero() {
+		return
+	}
+	missed, last := misfires(e, e.next(e.PrevTime), now)
+	if missed == 0 {
+		return
+	}
+
+	runs := 1
+	if now.Sub(last) > e.MisfireThreshold {
+		runs = e.misfireRuns(missed)
+	}
+	if runs > 0 && !c.claim(e, last) {
+		return
+	}
+	c.logf("cron: %s missed %d runs since %s, firing %d", e.Name, missed, e.PrevTime, runs)
+	for i := 0; i < runs; i++ {
+		c.dispatch(e)
+	}
+	if runs > 0 {
+		e.PrevTime = last
+	}
+}
+
+// fire runs e, which was due at e.RunTime, at now, and updates its PrevTime.
+// If the run is later than the entry's misfire threshold, the runs missed in
+// between are handled by its misfire policy. A zero threshold disables this
+// check.
+func (c *Cron) fire(e *JobEntry, now time.Time) {
+	runs, last := 1, e.NextTime
+	if e.MisfireThreshold > 0 && now.Sub(e.RunTime) > e.MisfireThreshold {
+		var missed int
+		missed, last = misfires(e, e.NextTime, e.planned(now))
+		runs = e.misfireRuns(missed)
+		c.logf("cron: %s misfired %d runs since %s, firing %d", e.Name, missed, e.NextTime, runs)
+	}
+	if runs > 0 && !c.claim(e, e.NextTime) {
+		return
+	}
+	for i := 0; i < runs; i++ {
+		c.dispatch(e)
+	}
+	if runs > 0 {
+		e.PrevTime = last
+	}
+}
+
+// misfireRuns returns how many runs the policy fires for missed runs.
+func (e *JobEntry) misfireRuns(missed int) int {
+	switch e.Misfire {
+	case MisfireFireOnce:
+		return 1
+	case MisfireFireAll:
+		if e.MaxRuns > 0 && missed > e.MaxRuns-e.Count {
+			return e.MaxRuns - e.Count
+		}
+		return missed
+	}
+	return 0
+}
+
+// misfires counts the activations of e from next up to now, included, and
+// returns the last of them. It stops counting at the entry's MaxMisfires.
+func misfires(e *JobEntry, next, now time.Time) (int, time.Time) {
+	max := e.MaxMisfires
+	if max <= 0 {
+		max = DefaultMaxMisfires
+	}
+
+	var (
+		count int
+		last  time.Time
+	)
+	for !next.IsZero() && !next.After(now) {
+		if count < max {
+			count++
+		}
+		last = next
+		next = e.next(next)
+	}
+	return count, last
+}
@@ -0,0 +1,98 @@
+package cron
+
+import (
+	"testing"
+	"time"
+)
+
+// Test that the runs missed while the cron was down are caught up with,
+// according to the entry's misfire policy.
+func TestMisfireCatchUp(t *testing.T) {
+	tests := []struct {
+		policy    MisfirePolicy
+		max       int
+		threshold time.Duration
+		expected  int
+	}{
+		{MisfireIgnore, 0, 0, 0},
+		{MisfireFireOnce, 0, 0, 1},
+		{MisfireFireAll, 0, 0, 3},
+		{MisfireFireAll, 2, 0, 2},
+		// The last missed run, at 14:00, is within the threshold.
+		{MisfireIgnore, 0, time.Hour, 1},
+	}
+
+	for _, test := range tests {
+		clock := NewFakeClock(getTime("Mon Jul 9 14:45 2012"))
+		cron := NewWithClock(time.UTC, clock)
+		cron.AddEntry(&JobEntry{
+			Schedule:         mustParse(t, "0 0 * * * ?"),
+			Job:              Job2Wrapper(func(ctx *JobContext) {}),
+			PrevTime:         getTime("Mon Jul 9 11:00 2012"),
+			Misfire:          test.policy,
+			MaxMisfires:      test.max,
+			MisfireThreshold: test.threshold,
+		})
+		cron.Start()
+		clock.BlockUntil(1)
+
+		entry := cron.Entries()[0]
+		if entry.Count != test.expected {
+			t.Errorf("%s, max %d, threshold %s: (expected) %d != %d (actual) runs",
+				test.policy, test.max, test.threshold, test.expected, entry.Count)
+		}
+		if !entry.NextTime.Equal(getTime("Mon Jul 9 15:00 2012")) {
+			t.Errorf("%s: unexpected next time %v", test.policy, entry.NextTime)
+		}
+		cron.Stop()
+	}
+}
+
+// Test that a timer firing late, e.g. after the host was suspended, is
+// handled by the entry's misfire policy.
+func TestMisfireLateTimer(t *testing.T) {
+	tests := []struct {
+		policy    MisfirePolicy
+		threshold time.Duration
+		expected  int
+	}{
+		// Without threshold the late run fires once, as it always did.
+		{MisfireIgnore, 0, 1},
+		{MisfireIgnore, time.Minute, 0},
+		{MisfireFireOnce, time.Minute, 1},
+		{MisfireFireAll, time.Minute, 3},
+	}
+
+	for _, test := range tests {
+		clock := NewFakeClock(getTime("Mon Jul 9 14:45 2012"))
+		cron := NewWithClock(time.UTC, clock)
+		cron.AddEntry(&JobEntry{
+			Schedule:         mustParse(t, "0 0 * * * ?"),
+			Job:              Job2Wrapper(func(ctx *JobContext) {}),
+			Misfire:          test.policy,
+			MisfireThreshold: test.threshold,
+		})
+		cron.Start()
+		clock.BlockUntil(1)
+		clock.Set(getTime("Mon Jul 9 17:30 2012"))
+		clock.BlockUntil(1)
+
+		entry := cron.Entries()[0]
+		if entry.Count != test.expected {
+			t.Errorf("%s, threshold %s: (expected) %d != %d (actual) runs",
+				test.policy, test.threshold, test.expected, entry.Count)
+		}
+		if !entry.NextTime.Equal(getTime("Mon Jul 9 18:00 2012")) {
+			t.Errorf("%s: unexpected next time %v", test.policy, entry.NextTime)
+		}
+		cron.Stop()
+	}
+}
+
+func mustParse(t *testing.T, spec string) Schedule {
+	sched, err := Parse(spec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return sched
+}
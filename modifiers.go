@@ -0,0 +1,161 @@
+package cron
+
+import (
+	"strings"
+	"time"
+)
+
+// Quartz-style day modifiers, enabled by the DayModifiers parse option:
+//
+//   Day of month:  L     the last day of the month
+//                  L-n   n days before the last day of the month
+//                  nW    the weekday (Monday to Friday) nearest to day n
+//                  LW    the last weekday of the month
+//   Day of week:   L     Saturday, the last day of the week
+//                  dL    the last day d of the month, e.g. 5L or FRIL
+//                  d#n   the nth day d of the month, e.g. FRI#2
+//
+// Modifiers may be mixed with plain values in a comma-separated list.
+
+// parseDomModifiers sets the day-of-month modifiers found in field on s, and
+// returns the field without them.
+func parseDomModifiers(field string, s *SpecSchedule) (string, error) {
+	var rest []string
+	for _, expr := range strings.Split(field, ",") {
+		upper := strings.ToUpper(expr)
+		switch {
+		case upper == "L":
+			s.LastDom |= 1
+		case upper == "LW":
+			s.LastWeekdayDom = true
+		case strings.HasPrefix(upper, "L-"):
+			n, err := mustParseInt(expr[2:])
+			if err != nil {
+				return "", err
+			}
+			if n > dom.max-dom.min {
+				return "", parseErrorf(ParseErrorModifier, expr, "Offset from last day (%d) above maximum (%d): %s", n, dom.max-dom.min, expr)
+			}
+			s.LastDom |= 1 << n
+		case strings.HasSuffix(upper, "W"):
+			n, err := mustParseInt(expr[:len(expr)-1])
+			if err != nil {
+				return "", err
+			}
+			if n < dom.min || n > dom.max {
+				return "", parseErrorf(ParseErrorModifier, expr, "Nearest weekday (%d) out of range (%d-%d): %s", n, dom.min, dom.max, expr)
+			}
+			s.WeekdayDom |= 1 << n
+		default:
+			rest = append(rest, expr)
+		}
+	}
+	return strings.Join(rest, ","), nil
+}
+
+// parseDowModifiers sets the day-of-week modifiers found in field on s, and
+// returns the field without them.
+func parseDowModifiers(field string, s *SpecSchedule) (string, error) {
+	var rest []string
+	for _, expr := range strings.Split(field, ",") {
+		upper := strings.ToUpper(expr)
+		switch {
+		case upper == "L":
+			rest = append(rest, "6")
+		case strings.Contains(expr, "#"):
+			dayAndNth := strings.Split(expr, "#")
+			if len(dayAndNth) != 2 {
+				return "", parseErrorf(ParseErrorModifier, expr, "Too many hashes: %s", expr)
+			}
+			d, err := parseIntOrName(dayAndNth[0], dow.names)
+			if err != nil {
+				return "", err
+			}
+			n, err := mustParseInt(dayAndNth[1])
+			if err != nil {
+				return "", err
+			}
+			if d > dow.max {
+				return "", parseErrorf(ParseErrorModifier, expr, "Day of week (%d) above maximum (%d): %s", d, dow.max, expr)
+			}
+			if n < 1 || n > 5 {
+				return "", parseErrorf(ParseErrorModifier, expr, "Occurrence in month (%d) out of range (1-5): %s", n, expr)
+			}
+			s.NthDow |= 1 << (d*8 + n)
+		case len(expr) > 1 && strings.HasSuffix(upper, "L"):
+			d, err := parseIntOrName(expr[:len(expr)-1], dow.names)
+			if err != nil {
+				return "", err
+			}
+			if d > dow.max {
+				return "", parseErrorf(ParseErrorModifier, expr, "Day of week (%d) above maximum (%d): %s", d, dow.max, expr)
+			}
+			s.LastDow |= 1 << d
+		default:
+			rest = append(rest, expr)
+		}
+	}
+	return strings.Join(rest, ","), nil
+}
+
+// domModifiersMatch returns true if a day-of-month modifier of s matches t.
+func domModifiersMatch(s *SpecSchedule, t time.Time) bool {
+	if s.LastDom == 0 && s.WeekdayDom == 0 && !s.LastWeekdayDom {
+		return false
+	}
+	var (
+		day  = t.Day()
+		last = daysIn(t)
+	)
+	if 1<<uint(last-day)&s.LastDom > 0 {
+		return true
+	}
+	if s.LastWeekdayDom && day == nearestWeekday(t, last) {
+		return true
+	}
+	for n := day - 2; n <= day+2; n++ {
+		if n >= 1 && n <= last && 1<<uint(n)&s.WeekdayDom > 0 && day == nearestWeekday(t, n) {
+			return true
+		}
+	}
+	return false
+}
+
+// dowModifiersMatch returns true if a day-of-week modifier of s matches t.
+func dowModifiersMatch(s *SpecSchedule, t time.Time) bool {
+	if s.LastDow == 0 && s.NthDow == 0 {
+		return false
+	}
+	var (
+		weekday = uint(t.Weekday())
+		nth     = uint(t.Day()-1)/7 + 1
+	)
+	if 1<<weekday&s.LastDow > 0 && t.Day()+7 > daysIn(t) {
+		return true
+	}
+	return 1<<(weekday*8+nth)&s.NthDow > 0
+}
+
+// daysIn returns the number of days in the month of t.
+func daysIn(t time.Time) int {
+	return time.Date(t.Year(), t.Month()+1, 0, 0, 0, 0, 0, time.UTC).Day()
+}
+
+// nearestWeekday returns the weekday (Monday to Friday) nearest to the given
+// day of the month of t, without leaving the month.
+func nearestWeekday(t time.Time, day int) int {
+	last := daysIn(t)
+	switch time.Date(t.Year(), t.Month(), day, 0, 0, 0, 0, time.UTC).Weekday() {
+	case time.Saturday:
+		if day == 1 {
+			return day + 2
+		}
+		return day - 1
+	case time.Sunday:
+		if day == last {
+			return day - 2
+		}
+		return day + 1
+	}
+	return day
+}
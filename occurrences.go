@@ -0,0 +1,189 @@
+package cron
+
+import (
+	"math/bits"
+	"time"
+)
+
+// Iterator steps through the activation times of a schedule, optionally up to
+// a time or a number of activations:
+//
+//	it := cron.Iterate(schedule, time.Now()).Until(end).Limit(10)
+//	for it.Next() {
+//		fmt.Println(it.Time())
+//	}
+type Iterator struct {
+	schedule Schedule
+	t, to    time.Time
+	limit    int
+	count    int
+	done     bool
+}
+
+// Iterate returns an Iterator over the activation times of the schedule
+// after from.
+func Iterate(schedule Schedule, from time.Time) *Iterator {
+	return &Iterator{schedule: schedule, t: from}
+}
+
+// Until stops the iterator after the activations up to and including to.
+func (it *Iterator) Until(to time.Time) *Iterator {
+	it.to = to
+	return it
+}
+
+// Limit stops the iterator after n activations. Zero means no limit.
+func (it *Iterator) Limit(n int) *Iterator {
+	it.limit = n
+	return it
+}
+
+// Next advances the iterator to the next activation time, and reports whether
+// there is one.
+func (it *Iterator) Next() bool {
+	if it.done || it.limit > 0 && it.count >= it.limit {
+		it.done = true
+		return false
+	}
+	next := it.schedule.Next(it.t)
+	if next.IsZero() || !next.After(it.t) || !it.to.IsZero() && next.After(it.to) {
+		it.done = true
+		return false
+	}
+	it.t = next
+	it.count++
+	return true
+}
+
+// Time returns the activation time the iterator is at.
+func (it *Iterator) Time() time.Time {
+	return it.t
+}
+
+// Occurrences returns the activation times of the schedule after from, up to
+// and including to.
+func Occurrences(schedule Schedule, from, to time.Time) []time.Time {
+	var times []time.Time
+	for it := Iterate(schedule, from).Until(to); it.Next(); {
+		times = append(times, it.Time())
+	}
+	return times
+}
+
+// NextN returns the next n activation times of the schedule after from, or
+// fewer if the schedule ends.
+func NextN(schedule Schedule, from time.Time, n int) []time.Time {
+	times := make([]time.Time, 0, n)
+	for it := Iterate(schedule, from).Limit(n); it.Next(); {
+		times = append(times, it.Time())
+	}
+	return times
+}
+
+// Count returns the number of activations of the schedule after from, up to
+// and including to. SpecSchedule and ConstantDelaySchedule are counted
+// without visiting every activation; other schedules are iterated.
+func Count(schedule Schedule, from, to time.Time) int {
+	if !to.After(from) {
+		return 0
+	}
+	switch s := schedule.(type) {
+	case *SpecSchedule:
+		return s.count(from, to)
+	case ConstantDelaySchedule:
+		if s.Delay > 0 {
+			start := from.Add(-time.Duration(from.Nanosecond()))
+			return int(to.Sub(start) / s.Delay)
+		}
+	}
+	return iterateCount(schedule, from, to)
+}
+
+// Frequency returns the average number of activations of the schedule per
+// period between from and to, e.g. the runs per day with 24*time.Hour.
+func Frequency(schedule Schedule, from, to time.Time, per time.Duration) float64 {
+	if !to.After(from) {
+		return 0
+	}
+	return float64(Count(schedule, from, to)) * float64(per) / float64(to.Sub(from))
+}
+
+// iterateCount counts the activations after from, up to and including to, one
+// by one.
+func iterateCount(schedule Schedule, from, to time.Time) int {
+	n := 0
+	for it := Iterate(schedule, from).Until(to); it.Next(); {
+		n++
+	}
+	return n
+}
+
+// count implements Count. Whole days, and hours, away from daylight saving
+// transitions are counted from the bit sets of the schedule. The rest, at the
+// ends of the range and around transitions, are iterated.
+func (s *SpecSchedule) count(from, to time.Time) int {
+	loc := from.Location()
+	if s.Location != nil {
+		loc = s.Location
+	}
+	from, to = from.In(loc), to.In(loc)
+
+	var (
+		perHour = bits.OnesCount64(s.Minute&^starBit) * bits.OnesCount64(s.Second&^starBit)
+		perDay  = bits.OnesCount64(s.Hour&^starBit) * perHour
+		n       int
+	)
+	// within reports whether [a, b) is within (from, to].
+	within := func(a, b time.Time) bool {
+		return a.After(from) && !b.Add(-time.Nanosecond).After(to)
+	}
+	// iterate counts the activations in [a, b) within (from, to].
+	iterate := func(a, b time.Time) int {
+		if a = a.Add(-time.Nanosecond); a.Before(from) {
+			a = from
+		}
+		if b = b.Add(-time.Nanosecond); b.After(to) {
+			b = to
+		}
+		return iterateCount(s, a, b)
+	}
+
+	year, month, day := from.Date()
+	for i := 0; ; i++ {
+		start := time.Date(year, month, day+i, 0, 0, 0, 0, loc)
+		end := time.Date(year, month, day+i+1, 0, 0, 0, 0, loc)
+		if start.After(to) {
+			break
+		}
+		noon := time.Date(year, month, day+i, 12, 0, 0, 0, loc)
+		if 1<<uint(noon.Month())&s.Month == 0 || !s.Year.Contains(noon.Year()) || !dayMatches(s, noon) {
+			continue
+		}
+		if within(start, end) && steady(start, end) {
+			n += perDay
+			continue
+		}
+
+		for b := start; b.Before(end); b = b.Add(time.Hour) {
+			e := b.Add(time.Hour)
+			if e.After(end) {
+				e = end
+			}
+			if e.Sub(b) == time.Hour && b.Minute() == 0 && b.Second() == 0 && within(b, e) && steady(b, e) {
+				if 1<<uint(b.Hour())&s.Hour > 0 {
+					n += perHour
+				}
+				continue
+			}
+			n += iterate(b, e)
+		}
+	}
+	return n
+}
+
+// steady reports whether no daylight saving transition happens within three
+// hours of [a, b), where it could affect the activations in it.
+func steady(a, b time.Time) bool {
+	_, end := a.Add(-3 * time.Hour).ZoneBounds()
+	return end.IsZero() || !end.Before(b.Add(3*time.Hour))
+}
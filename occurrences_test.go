@@ -0,0 +1,128 @@
+package cron
+
+import (
+	"testing"
+	"time"
+)
+
+func TestOccurrences(t *testing.T) {
+	sched, _ := Parse("0 0/15 9-10 * * ?")
+	actual := Occurrences(sched, getTime("Mon Jul 9 09:30 2012"), getTime("Mon Jul 9 10:30 2012"))
+	expected := []string{"Mon Jul 9 09:45 2012", "Mon Jul 9 10:00 2012", "Mon Jul 9 10:15 2012", "Mon Jul 9 10:30 2012"}
+	if len(actual) != len(expected) {
+		t.Fatalf("expected %d occurrences, got %v", len(expected), actual)
+	}
+	for i := range expected {
+		if !actual[i].Equal(getTime(expected[i])) {
+			t.Errorf("%d: (expected) %v != %v (actual)", i, getTime(expected[i]), actual[i])
+		}
+	}
+
+	next := NextN(sched, getTime("Mon Jul 9 10:50 2012"), 3)
+	expected = []string{"Tue Jul 10 09:00 2012", "Tue Jul 10 09:15 2012", "Tue Jul 10 09:30 2012"}
+	if len(next) != len(expected) {
+		t.Fatalf("expected %d occurrences, got %v", len(expected), next)
+	}
+	for i := range expected {
+		if !next[i].Equal(getTime(expected[i])) {
+			t.Errorf("%d: (expected) %v != %v (actual)", i, getTime(expected[i]), next[i])
+		}
+	}
+}
+
+func TestIterator(t *testing.T) {
+	sched, _ := Parse("0 0 * * * ?")
+	from := getTime("Mon Jul 9 14:45 2012")
+
+	tests := []struct {
+		it    *Iterator
+		count int
+		last  string
+	}{
+		{Iterate(sched, from).Limit(5), 5, "Mon Jul 9 19:00 2012"},
+		{Iterate(sched, from).Until(getTime("Mon Jul 9 17:00 2012")), 3, "Mon Jul 9 17:00 2012"},
+		{Iterate(sched, from).Until(getTime("Mon Jul 9 17:00 2012")).Limit(2), 2, "Mon Jul 9 16:00 2012"},
+		{Iterate(sched, from).Until(getTime("Mon Jul 9 14:50 2012")), 0, "Mon Jul 9 14:45 2012"},
+		{Iterate(&ZeroSchedule{}, from), 0, "Mon Jul 9 14:45 2012"},
+	}
+	for i, c := range tests {
+		count := 0
+		for c.it.Next() {
+			count++
+		}
+		if count != c.count || !c.it.Time().Equal(getTime(c.last)) {
+			t.Errorf("%d: expected %d activations up to %s, got %d up to %v", i, c.count, c.last, count, c.it.Time())
+		}
+		if c.it.Next() {
+			t.Errorf("%d: expected a finished iterator to stay finished", i)
+		}
+	}
+}
+
+func TestCount(t *testing.T) {
+	parser := NewParser(Second | Minute | Hour | Dom | Month | DowOptional | Descriptor | DayModifiers | YearOptional)
+	dst := NewParser(Second | Minute | Hour | Dom | Month | DowOptional | DSTNextValid | DSTFirstOccurrence)
+	ny, _ := time.LoadLocation("America/New_York")
+	lordHowe, _ := time.LoadLocation("Australia/Lord_Howe")
+
+	tests := []struct {
+		parser   Parser
+		spec     string
+		from, to time.Time
+	}{
+		{parser, "0 0/15 9-17 * * MON-FRI", getTime("Mon Jul 9 14:45:30 2012"), getTime("Wed Aug 15 10:07 2012")},
+		{parser, "0 0 0 L * ?", getTime("Mon Jul 9 14:45 2012"), getTime("Mon Jul 9 14:45 2014")},
+		{parser, "30 */7 */5 ? * FRI#2,MON", getTime("Mon Jul 9 14:45 2012"), getTime("Sat Dec 1 00:00 2012")},
+		{parser, "0 0 12 * * ? 2013", getTime("Mon Jul 9 14:45 2012"), getTime("Mon Jul 9 14:45 2015")},
+		{parser, "*/20 * * * * ?", getTime("Mon Jul 9 14:45:10 2012"), getTime("Tue Jul 10 14:45:10 2012")},
+		{parser, "0 30 * * * ?", getTime("Mon Jul 9 14:30 2012"), getTime("Mon Jul 9 16:30 2012")},
+		{parser, "0 0 2 * * ?", time.Date(2016, 3, 1, 0, 0, 0, 0, ny), time.Date(2016, 11, 30, 0, 0, 0, 0, ny)},
+		{parser, "0 */10 1-3 * * ?", time.Date(2016, 3, 12, 5, 0, 0, 0, ny), time.Date(2016, 11, 7, 5, 0, 0, 0, ny)},
+		{dst, "0 */10 1-3 * * ?", time.Date(2016, 3, 12, 5, 0, 0, 0, ny), time.Date(2016, 11, 7, 5, 0, 0, 0, ny)},
+		{parser, "0 */15 * * * ?", time.Date(2016, 3, 1, 0, 0, 0, 0, lordHowe), time.Date(2016, 10, 30, 0, 0, 0, 0, lordHowe)},
+		{parser, "CRON_TZ=America/New_York 0 30 1 * * ?", getTime("Sat Oct 1 00:00 2016"), getTime("Wed Nov 30 00:00 2016")},
+	}
+
+	for _, c := range tests {
+		sched, err := c.parser.Parse(c.spec)
+		if err != nil {
+			t.Error(err)
+			continue
+		}
+		expected := iterateCount(sched, c.from, c.to)
+		if actual := Count(sched, c.from, c.to); actual != expected {
+			t.Errorf("%s from %v to %v: (expected) %d != %d (actual)", c.spec, c.from, c.to, expected, actual)
+		}
+	}
+
+	// Every second of a year, counted without iterating over it.
+	sched, _ := Parse("* * * * * ?")
+	if actual := Count(sched, getTime("Sun Jan 1 00:00 2012"), getTime("Tue Jan 1 00:00 2013")); actual != 366*24*60*60 {
+		t.Errorf("expected a run every second of 2012, got %d", actual)
+	}
+
+	for _, c := range []struct {
+		delay    time.Duration
+		from, to string
+	}{
+		{time.Minute, "Mon Jul 9 14:45 2012", "Mon Jul 9 15:45 2012"},
+		{7 * time.Second, "Mon Jul 9 14:45:00.5 2012", "Mon Jul 9 15:45:03 2012"},
+	} {
+		from, to := getTime(c.from), getTime(c.to)
+		if actual, expected := Count(Every(c.delay), from, to), iterateCount(Every(c.delay), from, to); actual != expected {
+			t.Errorf("every %v from %v to %v: (expected) %d != %d (actual)", c.delay, from, to, expected, actual)
+		}
+	}
+}
+
+func TestFrequency(t *testing.T) {
+	sched, _ := Parse("0 */15 9-17 * * MON-FRI")
+	from := getTime("Mon Jul 9 00:00 2012")
+	to := from.AddDate(0, 0, 7)
+	if actual := Frequency(sched, from, to, 24*time.Hour); actual != 5*9*4/7.0 {
+		t.Errorf("expected %v runs per day, got %v", 5*9*4/7.0, actual)
+	}
+	if actual := Frequency(Every(time.Hour), from, to, 24*time.Hour); actual != 24 {
+		t.Errorf("expected 24 runs per day, got %v", actual)
+	}
+}
@@ -0,0 +1,39 @@
+package cron
+
+import "errors"
+
+// ErrTimeout is the error of a run which exceeded its JobEntry.Timeout.
+var ErrTimeout = errors.New("cron: job timed out")
+
+// Outcome is the result of a finished run.
+type Outcome int
+
+const (
+	// OutcomeNone means the job has not finished a run yet.
+	OutcomeNone Outcome = iota
+	// OutcomeSuccess means the job returned without error.
+	OutcomeSuccess
+	// OutcomeError means the job returned an error.
+	OutcomeError
+	// OutcomePanic means the job panicked.
+	OutcomePanic
+	// OutcomeTimeout means the job exceeded its timeout, whether it returned
+	// afterwards or was abandoned.
+	OutcomeTimeout
+)
+
+func (o Outcome) String() string {
+	switch o {
+	case OutcomeNone:
+		return "none"
+	case OutcomeSuccess:
+		return "success"
+	case OutcomeError:
+		return "error"
+	case OutcomePanic:
+		return "panic"
+	case OutcomeTimeout:
+		return "timeout"
+	}
+	return "unknown"
+}
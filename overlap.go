@@ -0,0 +1,163 @@
+package cron
+
+import (
+	"context"
+	"sync"
+)
+
+// OverlapPolicy decides what happens when an entry becomes due while a
+// previous run of it is still active.
+type OverlapPolicy int
+
+const (
+	// OverlapAllow starts a new run concurrently with the active ones.
+	OverlapAllow OverlapPolicy = iota
+	// OverlapSkip drops the new run.
+	OverlapSkip
+	// OverlapQueue delays the new run until the active one has finished.
+	// At most JobEntry.MaxQueued runs are kept waiting, others are dropped.
+	OverlapQueue
+	// OverlapReplace cancels the context of the active run and starts the new
+	// run without waiting for the old one to return.
+	OverlapReplace
+)
+
+func (p OverlapPolicy) String() string {
+	switch p {
+	case OverlapAllow:
+		return "allow"
+	case OverlapSkip:
+		return "skip"
+	case OverlapQueue:
+		return "queue"
+	case OverlapReplace:
+		return "replace"
+	}
+	return "unknown"
+}
+
+// entryState tracks the runs of an entry, shared by the scheduler loop and
+// the goroutines running the job.
+type entryState struct {
+	mux     sync.Mutex
+	active  int
+	queue   []int
+	cancel  context.CancelFunc
+	outcome Outcome
+	err     error
+}
+
+// dispatch starts a run of the entry according to its overlap policy. It is
+// called from the scheduler loop only.
+func (c *Cron) dispatch(e *JobEntry) {
+	s := e.state
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	if s.active > 0 {
+		switch e.Overlap {
+		case OverlapSkip:
+			c.logf("cron: skipped %s, previous run still active", e.Name)
+			return
+
+		case OverlapQueue:
+			max := e.MaxQueued
+			if max <= 0 {
+				max = 1
+			}
+			if len(s.queue) >= max {
+				c.logf("cron: dropped %s, %d runs already queued", e.Name, len(s.queue))
+				return
+			}
+			e.Count++
+			s.queue = append(s.queue, e.Count)
+			return
+
+		case OverlapReplace:
+			if s.cancel != nil {
+				s.cancel()
+			}
+		}
+	}
+
+	e.Count++
+	s.active++
+	c.begin(e.Name)
+	// Register the cancel func of the run before it starts, so that the run
+	// replacing it never misses it.
+	ctx, cancel := context.WithCancel(e.ctx)
+	s.cancel = cancel
+	go c.run(e, e.ctx, ctx, cancel, e.Count)
+}
+
+// run invokes the job with the context of the run, derived from parent,
+// followed by the runs queued behind it.
+func (c *Cron) run(entry *JobEntry, parent, ctx context.Context, cancel context.CancelFunc, count int) {
+	for {
+		c.attempt(entry, ctx, count)
+		cancel()
+
+		next, ok := entry.state.next(parent, c.draining())
+		if ok {
+			// Register the next run before finishing this one, so that
+			// Shutdown never observes zero runs in between.
+			c.begin(entry.Name)
+		}
+		c.finish(entry.Name)
+		if !ok {
+			return
+		}
+		count = next
+		ctx, cancel = entry.state.watch(parent)
+	}
+}
+
+// next pops the count of the next queued run. Queued runs are dropped once
+// ctx, the parent of the runs, is cancelled or drain is closed by Shutdown.
+func (s *entryState) next(ctx context.Context, drain <-chan struct{}) (int, bool) {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	if len(s.queue) == 0 || ctx.Err() != nil || closed(drain) {
+		s.queue = nil
+		s.active--
+		return 0, false
+	}
+	count := s.queue[0]
+	s.queue = s.queue[1:]
+	return count, true
+}
+
+// closed reports whether ch is closed, without blocking.
+func closed(ch <-chan struct{}) bool {
+	select {
+	case <-ch:
+		return true
+	default:
+		return false
+	}
+}
+
+// watch derives the context of a single run from ctx, remembering its cancel
+// func for OverlapReplace.
+func (s *entryState) watch(ctx context.Context) (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancel(ctx)
+	s.mux.Lock()
+	s.cancel = cancel
+	s.mux.Unlock()
+	return ctx, cancel
+}
+
+// record stores the outcome of a finished attempt.
+func (s *entryState) record(outcome Outcome, err error) {
+	s.mux.Lock()
+	s.outcome, s.err = outcome, err
+	s.mux.Unlock()
+}
+
+// last returns the outcome of the last finished attempt.
+func (s *entryState) last() (Outcome, error) {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	return s.outcome, s.err
+}
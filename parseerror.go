@@ -0,0 +1,184 @@
+package cron
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ParseErrorKind classifies the problem reported by a ParseError.
+type ParseErrorKind int
+
+const (
+	// ParseErrorSyntax is a malformed expression, such as too many hyphens.
+	ParseErrorSyntax ParseErrorKind = iota
+	// ParseErrorEmpty is an empty spec.
+	ParseErrorEmpty
+	// ParseErrorFieldCount is a spec with too few or too many fields.
+	ParseErrorFieldCount
+	// ParseErrorNumber is a value which is not a number, or is negative.
+	ParseErrorNumber
+	// ParseErrorName is an unknown month or day of week name.
+	ParseErrorName
+	// ParseErrorRange is a value or range outside the bounds of its field.
+	ParseErrorRange
+	// ParseErrorStep is a step which is not a positive number.
+	ParseErrorStep
+	// ParseErrorModifier is a malformed L, W or # day modifier.
+	ParseErrorModifier
+	// ParseErrorDescriptor is an unknown descriptor or @every duration.
+	ParseErrorDescriptor
+	// ParseErrorTimeZone is a missing or unknown CRON_TZ= time zone.
+	ParseErrorTimeZone
+)
+
+func (k ParseErrorKind) String() string {
+	switch k {
+	case ParseErrorSyntax:
+		return "syntax"
+	case ParseErrorEmpty:
+		return "empty"
+	case ParseErrorFieldCount:
+		return "field count"
+	case ParseErrorNumber:
+		return "number"
+	case ParseErrorName:
+		return "name"
+	case ParseErrorRange:
+		return "range"
+	case ParseErrorStep:
+		return "step"
+	case ParseErrorModifier:
+		return "modifier"
+	case ParseErrorDescriptor:
+		return "descriptor"
+	case ParseErrorTimeZone:
+		return "time zone"
+	}
+	return "unknown"
+}
+
+// ParseError describes a spec which failed to parse, and where. Errors
+// returned by Parser.Parse and its variants are of this type:
+//
+//	var perr *cron.ParseError
+//	if _, err := cron.Parse(spec); errors.As(err, &perr) {
+//		highlight(perr.Offset, len(perr.Token))
+//	}
+type ParseError struct {
+	// Spec is the spec as given to the parser.
+	Spec string
+
+	// Field names the field of the error, such as "minute" or "day of week".
+	// It is empty for errors about the spec as a whole.
+	Field string
+
+	// Index is the position of the field in Spec, counting from 0 and
+	// excluding a time zone prefix, or -1 for errors about the spec as a whole.
+	Index int
+
+	// Offset is the byte offset of Token in Spec.
+	Offset int
+
+	// Token is the offending part of Spec.
+	Token string
+
+	// Kind classifies the error.
+	Kind ParseErrorKind
+
+	// Msg describes the error.
+	Msg string
+
+	// Err is the underlying error, if any, such as from time.LoadLocation.
+	Err error
+}
+
+func (e *ParseError) Error() string {
+	if e.Field == "" {
+		return e.Msg
+	}
+	return fmt.Sprintf("%s (%s field)", e.Msg, e.Field)
+}
+
+// Unwrap returns the underlying error.
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
+
+// parseErrorf returns a ParseError of the given kind about token, which is
+// located in the spec by the parser.
+func parseErrorf(kind ParseErrorKind, token, format string, args ...interface{}) *ParseError {
+	return &ParseError{
+		Index: -1,
+		Token: token,
+		Kind:  kind,
+		Msg:   fmt.Sprintf(format, args...),
+	}
+}
+
+// wrap sets the underlying error of e.
+func (e *ParseError) wrap(err error) *ParseError {
+	e.Err = err
+	return e
+}
+
+// The names of the fields, by position in places.
+var fieldNames = []string{
+	"second",
+	"minute",
+	"hour",
+	"day of month",
+	"month",
+	"day of week",
+	"year",
+}
+
+// locate sets the field of err, if it is a ParseError, to the one at the given
+// position of places. indexes maps positions to the fields written in the
+// spec, which start at the given offsets.
+func locate(err error, position int, fields []string, indexes, offsets []int) error {
+	e, ok := err.(*ParseError)
+	if !ok || e.Field != "" {
+		return err
+	}
+	e.Field = fieldNames[position]
+	e.Index = indexes[position]
+	if e.Index >= 0 {
+		e.Offset = offsets[e.Index]
+		if i := strings.Index(fields[e.Index], e.Token); i >= 0 && e.Token != "" {
+			e.Offset += i
+		}
+	}
+	return e
+}
+
+// fieldOffsets returns the byte offsets of the whitespace separated fields of
+// spec, starting from base.
+func fieldOffsets(spec string, base int) []int {
+	var (
+		offsets []int
+		inField bool
+	)
+	for i, c := range spec {
+		space := c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == '\v' || c == '\f'
+		if !space && !inField {
+			offsets = append(offsets, base+i)
+		}
+		inField = !space
+	}
+	return offsets
+}
+
+// fieldIndexes returns, for each position of places, the index of the field
+// written for it in a spec of count fields, or -1 if it takes the default.
+func fieldIndexes(count int, options ParseOption) []int {
+	indexes := make([]int, len(places))
+	n := 0
+	for i, place := range places {
+		indexes[i] = -1
+		if options&place > 0 && n < count {
+			indexes[i] = n
+			n++
+		}
+	}
+	return indexes
+}
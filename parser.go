@@ -15,14 +15,21 @@ import (
 type ParseOption int
 
 const (
-	Second      ParseOption = 1 << iota // Seconds field, default 0
-	Minute                              // Minutes field, default 0
-	Hour                                // Hours field, default 0
-	Dom                                 // Day of month field, default *
-	Month                               // Month field, default *
-	Dow                                 // Day of week field, default *
-	DowOptional                         // Optional day of week field, default *
-	Descriptor                          // Allow descriptors such as @monthly, @weekly, etc.
+	Second       ParseOption = 1 << iota // Seconds field, default 0
+	Minute                               // Minutes field, default 0
+	Hour                                 // Hours field, default 0
+	Dom                                  // Day of month field, default *
+	Month                                // Month field, default *
+	Dow                                  // Day of week field, default *
+	DowOptional                          // Optional day of week field, default *
+	Descriptor                           // Allow descriptors such as @monthly, @weekly, etc.
+	DayModifiers                         // Allow L, W and # in day fields, such as L-3, 15W or FRI#2
+	Year                                 // Year field, default *
+	YearOptional                         // Optional year field, default *
+
+	DSTNextValid        // Run activations in a daylight saving gap at the end of the gap
+	DSTFirstOccurrence  // Run ambiguous activations only before clocks are turned back
+	DSTSecondOccurrence // Run ambiguous activations only after clocks are turned back
 )
 
 var places = []ParseOption{
@@ -32,6 +39,7 @@ var places = []ParseOption{
 	Dom,
 	Month,
 	Dow,
+	Year,
 }
 
 var defaults = []string{
@@ -41,6 +49,7 @@ var defaults = []string{
 	"*",
 	"*",
 	"*",
+	"*",
 }
 
 // A custom Parser that can be configured.
@@ -51,36 +60,93 @@ type Parser struct {
 
 // Creates a custom Parser with custom options.
 //
-//  // Standard parser without descriptors
-//  specParser := NewParser(Minute | Hour | Dom | Month | Dow)
-//  sched, err := specParser.Parse("0 0 15 */3 *")
+//	// Standard parser without descriptors
+//	specParser := NewParser(Minute | Hour | Dom | Month | Dow)
+//	sched, err := specParser.Parse("0 0 15 */3 *")
 //
-//  // Same as above, just excludes time fields
-//  subsParser := NewParser(Dom | Month | Dow)
-//  sched, err := specParser.Parse("15 */3 *")
+//	// Same as above, just excludes time fields
+//	subsParser := NewParser(Dom | Month | Dow)
+//	sched, err := specParser.Parse("15 */3 *")
 //
-//  // Same as above, just makes Dow optional
-//  subsParser := NewParser(Dom | Month | DowOptional)
-//  sched, err := specParser.Parse("15 */3")
+//	// Same as above, just makes Dow optional
+//	subsParser := NewParser(Dom | Month | DowOptional)
+//	sched, err := specParser.Parse("15 */3")
 //
+//	// Quartz-style, with seconds and an optional year
+//	quartzParser := NewParser(Second | Minute | Hour | Dom | Month | Dow | YearOptional)
+//	sched, err := quartzParser.Parse("0 0 12 ? * MON 2027")
 func NewParser(options ParseOption) Parser {
 	optionals := 0
 	if options&DowOptional > 0 {
 		options |= Dow
 		optionals++
 	}
+	if options&YearOptional > 0 {
+		options |= Year
+		optionals++
+	}
 	return Parser{options, optionals}
 }
 
 // Parse returns a new crontab schedule representing the given spec.
 // It returns a descriptive error if the spec is not valid.
-// It accepts crontab specs and features configured by NewParser.
+// It accepts crontab specs and features configured by NewParser, optionally
+// prefixed by a time zone such as "CRON_TZ=Asia/Shanghai " or "TZ=UTC ".
 func (p Parser) Parse(spec string) (Schedule, error) {
+	return p.ParseWithName(spec, "")
+}
+
+// ParseWithName is like Parse, but seeds the H tokens of the spec with the
+// given name, usually the name of the entry the schedule is for.
+func (p Parser) ParseWithName(spec, name string) (Schedule, error) {
+	schedule, err := p.parse(spec, name)
+	if e, ok := err.(*ParseError); ok {
+		e.Spec = spec
+	}
+	return schedule, err
+}
+
+// parse implements ParseWithName. Its errors are left for the caller to
+// complete with the spec.
+func (p Parser) parse(spec, name string) (Schedule, error) {
 	if len(spec) == 0 {
-		return nil, fmt.Errorf("Empty spec string")
+		return nil, parseErrorf(ParseErrorEmpty, "", "Empty spec string")
 	}
+
+	// Extract the time zone of the spec, if any.
+	var (
+		loc  *time.Location
+		base int
+	)
+	if strings.HasPrefix(spec, "TZ=") || strings.HasPrefix(spec, "CRON_TZ=") {
+		var (
+			eq    = strings.Index(spec, "=")
+			space = strings.IndexAny(spec, " \t")
+			err   error
+		)
+		if space == -1 || strings.TrimSpace(spec[space:]) == "" {
+			return nil, parseErrorf(ParseErrorTimeZone, "", "Missing spec after time zone: %s", spec)
+		}
+		if loc, err = time.LoadLocation(spec[eq+1 : space]); err != nil {
+			e := parseErrorf(ParseErrorTimeZone, spec[eq+1:space], "Failed to load time zone %s: %s", spec[eq+1:space], err)
+			e.Offset = eq + 1
+			return nil, e.wrap(err)
+		}
+		rest := spec[space:]
+		base = space + len(rest) - len(strings.TrimLeft(rest, " \t"))
+		spec = strings.TrimSpace(rest)
+	}
+
 	if spec[0] == '@' && p.options&Descriptor > 0 {
-		return parseDescriptor(spec)
+		schedule, err := parseDescriptor(spec)
+		if e, ok := err.(*ParseError); ok {
+			e.Offset = base
+		}
+		if s, ok := schedule.(*SpecSchedule); ok {
+			s.Location = loc
+			p.setDST(s)
+		}
+		return schedule, err
 	}
 
 	// Figure out how many fields we need
@@ -97,45 +163,90 @@ func (p Parser) Parse(spec string) (Schedule, error) {
 
 	// Validate number of fields
 	if count := len(fields); count < min || count > max {
+		e := parseErrorf(ParseErrorFieldCount, spec, "Expected %d to %d fields, found %d: %s", min, max, count, spec)
 		if min == max {
-			return nil, fmt.Errorf("Expected exactly %d fields, found %d: %s", min, count, spec)
+			e.Msg = fmt.Sprintf("Expected exactly %d fields, found %d: %s", min, count, spec)
 		}
-		return nil, fmt.Errorf("Expected %d to %d fields, found %d: %s", min, max, count, spec)
+		e.Offset = base
+		return nil, e
 	}
 
+	// Locate errors by the fields as written.
+	var (
+		written = fields
+		indexes = fieldIndexes(len(fields), p.options)
+		offsets = fieldOffsets(spec, base)
+	)
+
 	// Fill in missing fields
 	fields = expandFields(fields, p.options)
 
 	var err error
-	field := func(field string, r bounds) uint64 {
+	for i, r := range []bounds{seconds, minutes, hours, dom, months, dow} {
+		if fields[i], err = expandHash(fields[i], r, name, i); err != nil {
+			return nil, locate(err, i, written, indexes, offsets)
+		}
+	}
+
+	schedule := &SpecSchedule{}
+	if p.options&DayModifiers > 0 {
+		if fields[3], err = parseDomModifiers(fields[3], schedule); err != nil {
+			return nil, locate(err, 3, written, indexes, offsets)
+		}
+		if fields[5], err = parseDowModifiers(fields[5], schedule); err != nil {
+			return nil, locate(err, 5, written, indexes, offsets)
+		}
+	}
+
+	field := func(position int, r bounds) uint64 {
 		if err != nil {
 			return 0
 		}
 		var bits uint64
-		bits, err = getField(field, r)
+		if bits, err = getField(fields[position], r); err != nil {
+			err = locate(err, position, written, indexes, offsets)
+		}
 		return bits
 	}
 
 	var (
-		second     = field(fields[0], seconds)
-		minute     = field(fields[1], minutes)
-		hour       = field(fields[2], hours)
-		dayofmonth = field(fields[3], dom)
-		month      = field(fields[4], months)
-		dayofweek  = field(fields[5], dow)
+		second     = field(0, seconds)
+		minute     = field(1, minutes)
+		hour       = field(2, hours)
+		dayofmonth = field(3, dom)
+		month      = field(4, months)
+		dayofweek  = field(5, dow)
 	)
 	if err != nil {
 		return nil, err
 	}
+	if schedule.Year, err = getYearField(fields[6]); err != nil {
+		return nil, locate(err, 6, written, indexes, offsets)
+	}
+
+	schedule.Second = second
+	schedule.Minute = minute
+	schedule.Hour = hour
+	schedule.Dom = dayofmonth
+	schedule.Month = month
+	schedule.Dow = dayofweek
+	schedule.Location = loc
+	p.setDST(schedule)
+	return schedule, nil
+}
 
-	return &SpecSchedule{
-		Second: second,
-		Minute: minute,
-		Hour:   hour,
-		Dom:    dayofmonth,
-		Month:  month,
-		Dow:    dayofweek,
-	}, nil
+// setDST sets the daylight saving policies selected by the parse options on s.
+// DSTFirstOccurrence takes precedence over DSTSecondOccurrence.
+func (p Parser) setDST(s *SpecSchedule) {
+	if p.options&DSTNextValid > 0 {
+		s.Gap = GapNextValid
+	}
+	switch {
+	case p.options&DSTFirstOccurrence > 0:
+		s.Fold = FoldFirst
+	case p.options&DSTSecondOccurrence > 0:
+		s.Fold = FoldSecond
+	}
 }
 
 func expandFields(fields []string, options ParseOption) []string {
@@ -185,6 +296,12 @@ func Parse(spec string) (Schedule, error) {
 	return defaultParser.Parse(spec)
 }
 
+// ParseWithName is like Parse, but seeds the H tokens of the spec, such as
+// "0 H/15 * * * ?", with the given name.
+func ParseWithName(spec, name string) (Schedule, error) {
+	return defaultParser.ParseWithName(spec, name)
+}
+
 // getField returns an Int with the bits set representing all of the times that
 // the field represents or error parsing field value.  A "field" is a comma-separated
 // list of "ranges".
@@ -202,26 +319,46 @@ func getField(field string, r bounds) (uint64, error) {
 }
 
 // getRange returns the bits indicated by the given expression:
-//   number | number "-" number [ "/" number ]
-// or error parsing range.
+//
+//	number | number "-" number [ "/" number ]
+//
+// or error parsing range. A range whose beginning is beyond its end wraps
+// around: "22-2" in hours is 22, 23, 0, 1 and 2. The step counts on across the
+// wrap, so "22-3/2" is 22, 0 and 2.
 func getRange(expr string, r bounds) (uint64, error) {
+	start, end, step, star, err := parseRange(expr, r)
+	if err != nil {
+		return 0, err
+	}
+
+	var extra uint64
+	if star {
+		extra = starBit
+	}
+	if start > end {
+		return getWrapBits(start, end, step, r) | extra, nil
+	}
+	return getBits(start, end, step) | extra, nil
+}
+
+// parseRange returns the start, end and step of the given range expression,
+// and whether it is a star, or error parsing range. The start may be beyond
+// the end for a wrap-around range.
+func parseRange(expr string, r bounds) (start, end, step uint, star bool, err error) {
 	var (
-		start, end, step uint
-		rangeAndStep     = strings.Split(expr, "/")
-		lowAndHigh       = strings.Split(rangeAndStep[0], "-")
-		singleDigit      = len(lowAndHigh) == 1
-		err              error
+		rangeAndStep = strings.Split(expr, "/")
+		lowAndHigh   = strings.Split(rangeAndStep[0], "-")
+		singleDigit  = len(lowAndHigh) == 1
 	)
 
-	var extra uint64
 	if lowAndHigh[0] == "*" || lowAndHigh[0] == "?" {
 		start = r.min
 		end = r.max
-		extra = starBit
+		star = true
 	} else {
 		start, err = parseIntOrName(lowAndHigh[0], r.names)
 		if err != nil {
-			return 0, err
+			return
 		}
 		switch len(lowAndHigh) {
 		case 1:
@@ -229,10 +366,11 @@ func getRange(expr string, r bounds) (uint64, error) {
 		case 2:
 			end, err = parseIntOrName(lowAndHigh[1], r.names)
 			if err != nil {
-				return 0, err
+				return
 			}
 		default:
-			return 0, fmt.Errorf("Too many hyphens: %s", expr)
+			err = parseErrorf(ParseErrorSyntax, expr, "Too many hyphens: %s", expr)
+			return
 		}
 	}
 
@@ -242,7 +380,7 @@ func getRange(expr string, r bounds) (uint64, error) {
 	case 2:
 		step, err = mustParseInt(rangeAndStep[1])
 		if err != nil {
-			return 0, err
+			return
 		}
 
 		// Special handling: "N/step" means "N-max/step".
@@ -250,23 +388,18 @@ func getRange(expr string, r bounds) (uint64, error) {
 			end = r.max
 		}
 	default:
-		return 0, fmt.Errorf("Too many slashes: %s", expr)
+		err = parseErrorf(ParseErrorSyntax, expr, "Too many slashes: %s", expr)
+		return
 	}
 
 	if start < r.min {
-		return 0, fmt.Errorf("Beginning of range (%d) below minimum (%d): %s", start, r.min, expr)
-	}
-	if end > r.max {
-		return 0, fmt.Errorf("End of range (%d) above maximum (%d): %s", end, r.max, expr)
-	}
-	if start > end {
-		return 0, fmt.Errorf("Beginning of range (%d) beyond end of range (%d): %s", start, end, expr)
-	}
-	if step == 0 {
-		return 0, fmt.Errorf("Step of range should be a positive number: %s", expr)
+		err = parseErrorf(ParseErrorRange, expr, "Beginning of range (%d) below minimum (%d): %s", start, r.min, expr)
+	} else if end > r.max {
+		err = parseErrorf(ParseErrorRange, expr, "End of range (%d) above maximum (%d): %s", end, r.max, expr)
+	} else if step == 0 {
+		err = parseErrorf(ParseErrorStep, expr, "Step of range should be a positive number: %s", expr)
 	}
-
-	return getBits(start, end, step) | extra, nil
+	return
 }
 
 // parseIntOrName returns the (possibly-named) integer contained in expr.
@@ -276,17 +409,21 @@ func parseIntOrName(expr string, names map[string]uint) (uint, error) {
 			return namedInt, nil
 		}
 	}
-	return mustParseInt(expr)
+	num, err := mustParseInt(expr)
+	if e, ok := err.(*ParseError); ok && names != nil && e.Err != nil {
+		e.Kind = ParseErrorName
+	}
+	return num, err
 }
 
 // mustParseInt parses the given expression as an int or returns an error.
 func mustParseInt(expr string) (uint, error) {
 	num, err := strconv.Atoi(expr)
 	if err != nil {
-		return 0, fmt.Errorf("Failed to parse int from %s: %s", expr, err)
+		return 0, parseErrorf(ParseErrorNumber, expr, "Failed to parse int from %s: %s", expr, err).wrap(err)
 	}
 	if num < 0 {
-		return 0, fmt.Errorf("Negative number (%d) not allowed: %s", num, expr)
+		return 0, parseErrorf(ParseErrorNumber, expr, "Negative number (%d) not allowed: %s", num, expr)
 	}
 
 	return uint(num), nil
@@ -308,6 +445,23 @@ func getBits(min, max, step uint) uint64 {
 	return bits
 }
 
+// getWrapBits sets the bits from min up to the bounds of r, and on from the
+// minimum of r to max, modulo the given step size.
+func getWrapBits(min, max, step uint, r bounds) uint64 {
+	var (
+		bits uint64
+		span = r.max - r.min + 1
+	)
+	for i := uint(0); i <= max+span-min; i += step {
+		v := min + i
+		if v > r.max {
+			v -= span
+		}
+		bits |= 1 << v
+	}
+	return bits
+}
+
 // all returns all bits within the given bounds.  (plus the star bit)
 func all(r bounds) uint64 {
 	return getBits(r.min, r.max, 1) | starBit
@@ -371,10 +525,10 @@ func parseDescriptor(descriptor string) (Schedule, error) {
 	if strings.HasPrefix(descriptor, every) {
 		duration, err := time.ParseDuration(descriptor[len(every):])
 		if err != nil {
-			return nil, fmt.Errorf("Failed to parse duration %s: %s", descriptor, err)
+			return nil, parseErrorf(ParseErrorDescriptor, descriptor, "Failed to parse duration %s: %s", descriptor, err).wrap(err)
 		}
 		return Every(duration), nil
 	}
 
-	return nil, fmt.Errorf("Unrecognized descriptor: %s", descriptor)
+	return nil, parseErrorf(ParseErrorDescriptor, descriptor, "Unrecognized descriptor: %s", descriptor)
 }
@@ -1,7 +1,11 @@
 package cron
 
 import (
+	"errors"
+	"fmt"
+	"math/bits"
 	"reflect"
+	"strconv"
 	"strings"
 	"testing"
 	"time"
@@ -30,6 +34,14 @@ func TestRange(t *testing.T) {
 		{"*", 1, 3, 1<<1 | 1<<2 | 1<<3 | starBit, ""},
 		{"*/2", 1, 3, 1<<1 | 1<<3 | starBit, ""},
 
+		{"5-3", 3, 5, 1<<5 | 1<<3, ""},
+		{"22-2", 0, 23, 1<<22 | 1<<23 | 1<<0 | 1<<1 | 1<<2, ""},
+		{"22-3/2", 0, 23, 1<<22 | 1<<0 | 1<<2, ""},
+		{"50-10/15", 0, 59, 1<<50 | 1<<5, ""},
+		{"30-2", 1, 31, 1<<30 | 1<<31 | 1<<1 | 1<<2, ""},
+		{"7-6", 0, 7, 0xff, ""},
+		{"6-5", 3, 7, 1<<6 | 1<<7 | 1<<3 | 1<<4 | 1<<5, ""},
+
 		{"5--5", 0, 0, zero, "Too many hyphens"},
 		{"jan-x", 0, 0, zero, "Failed to parse int from"},
 		{"2-x", 1, 5, zero, "Failed to parse int from"},
@@ -37,7 +49,6 @@ func TestRange(t *testing.T) {
 		{"*//2", 0, 0, zero, "Too many slashes"},
 		{"1", 3, 5, zero, "below minimum"},
 		{"6", 3, 5, zero, "above maximum"},
-		{"5-3", 3, 5, zero, "beyond end of range"},
 		{"*/0", 0, 0, zero, "should be a positive number"},
 	}
 
@@ -65,6 +76,7 @@ func TestField(t *testing.T) {
 		{"5,6", 1, 7, 1<<5 | 1<<6},
 		{"5,6,7", 1, 7, 1<<5 | 1<<6 | 1<<7},
 		{"1,5-7/2,3", 1, 7, 1<<1 | 1<<5 | 1<<7 | 1<<3},
+		{"1,6-2", 1, 7, 1<<6 | 1<<7 | 1<<1 | 1<<2},
 	}
 
 	for _, c := range fields {
@@ -202,8 +214,15 @@ func TestStandardSpecSchedule(t *testing.T) {
 		err      string
 	}{
 		{
-			expr:     "5 * * * *",
-			expected: &SpecSchedule{1 << seconds.min, 1 << 5, all(hours), all(dom), all(months), all(dow)},
+			expr: "5 * * * *",
+			expected: &SpecSchedule{
+				Second: 1 << seconds.min,
+				Minute: 1 << 5,
+				Hour:   all(hours),
+				Dom:    all(dom),
+				Month:  all(months),
+				Dow:    all(dow),
+			},
 		},
 		{
 			expr:     "@every 5m",
@@ -232,3 +251,338 @@ func TestStandardSpecSchedule(t *testing.T) {
 		}
 	}
 }
+
+func TestParseDayModifiers(t *testing.T) {
+	parser := NewParser(Second | Minute | Hour | Dom | Month | Dow | DayModifiers)
+	entries := []struct {
+		expr     string
+		expected *SpecSchedule
+		err      string
+	}{
+		{
+			expr: "0 0 0 L,L-3,15W,LW,1 * ?",
+			expected: &SpecSchedule{
+				Second:         1,
+				Minute:         1,
+				Hour:           1,
+				Dom:            1 << 1,
+				Month:          all(months),
+				Dow:            all(dow),
+				LastDom:        1 | 1<<3,
+				WeekdayDom:     1 << 15,
+				LastWeekdayDom: true,
+			},
+		},
+		{
+			expr: "0 0 0 ? * 5L,FRI#2,mon#1",
+			expected: &SpecSchedule{
+				Second:  1,
+				Minute:  1,
+				Hour:    1,
+				Dom:     all(dom),
+				Month:   all(months),
+				LastDow: 1 << 5,
+				NthDow:  1<<(5*8+2) | 1<<(1*8+1),
+			},
+		},
+		{expr: "0 0 0 L-31 * ?", err: "above maximum"},
+		{expr: "0 0 0 32W * ?", err: "out of range"},
+		{expr: "0 0 0 ? * FRI#6", err: "out of range"},
+		{expr: "0 0 0 ? * FRI#1#2", err: "Too many hashes"},
+		{expr: "0 0 0 ? * 7L", err: "above maximum"},
+	}
+
+	for _, c := range entries {
+		actual, err := parser.Parse(c.expr)
+		if len(c.err) != 0 && (err == nil || !strings.Contains(err.Error(), c.err)) {
+			t.Errorf("%s => expected %v, got %v", c.expr, c.err, err)
+		}
+		if len(c.err) == 0 && err != nil {
+			t.Errorf("%s => unexpected error %v", c.expr, err)
+		}
+		if c.expected != nil && !reflect.DeepEqual(actual, c.expected) {
+			t.Errorf("%s => expected %+v, got %+v", c.expr, c.expected, actual)
+		}
+	}
+
+	// Without the option, modifiers are not valid.
+	if _, err := Parse("0 0 0 L * ?"); err == nil {
+		t.Error("expected an error parsing L without DayModifiers")
+	}
+}
+
+func TestParseYear(t *testing.T) {
+	entries := []struct {
+		options ParseOption
+		expr    string
+		years   []int
+		err     string
+	}{
+		{Second | Minute | Hour | Dom | Month | Dow | Year, "0 0 0 * * ? *", nil, ""},
+		{Second | Minute | Hour | Dom | Month | Dow | Year, "0 0 0 * * ? 2027", []int{2027}, ""},
+		{Second | Minute | Hour | Dom | Month | Dow | Year, "0 0 0 * * ? 2098-2099,1970", []int{1970, 2098, 2099}, ""},
+		{Second | Minute | Hour | Dom | Month | Dow | Year, "0 0 0 * * ?", nil, "Expected exactly 7 fields"},
+		{Second | Minute | Hour | Dom | Month | Dow | Year, "0 0 0 * * ? 1969", nil, "below minimum"},
+		{Second | Minute | Hour | Dom | Month | Dow | Year, "0 0 0 * * ? 2100", nil, "above maximum"},
+		{Second | Minute | Hour | Dom | Month | Dow | Year, "0 0 0 * * ? 2030-2027", nil, "beyond end of range"},
+		{Second | Minute | Hour | Dom | Month | Dow | YearOptional, "0 0 0 * * ?", nil, ""},
+		{Second | Minute | Hour | Dom | Month | DowOptional | YearOptional, "0 0 0 * *", nil, ""},
+		{Second | Minute | Hour | Dom | Month | DowOptional | YearOptional, "0 0 0 * * ? 2030", []int{2030}, ""},
+		{Second | Minute | Hour | Dom | Month | Dow, "0 0 0 * * ? 2030", nil, "Expected exactly 6 fields"},
+	}
+
+	for _, c := range entries {
+		actual, err := NewParser(c.options).Parse(c.expr)
+		if len(c.err) != 0 && (err == nil || !strings.Contains(err.Error(), c.err)) {
+			t.Errorf("%s => expected %v, got %v", c.expr, c.err, err)
+		}
+		if len(c.err) == 0 && err != nil {
+			t.Errorf("%s => unexpected error %v", c.expr, err)
+		}
+		if err != nil {
+			continue
+		}
+
+		var expected YearSet
+		for _, year := range c.years {
+			expected.set(uint(year))
+		}
+		if year := actual.(*SpecSchedule).Year; year != expected {
+			t.Errorf("%s => expected years %v, got %b", c.expr, c.years, year)
+		}
+	}
+}
+
+func TestParseTz(t *testing.T) {
+	shanghai, _ := time.LoadLocation("Asia/Shanghai")
+	entries := []struct {
+		expr     string
+		location *time.Location
+		err      string
+	}{
+		{"CRON_TZ=Asia/Shanghai 0 0 9 * * ?", shanghai, ""},
+		{"TZ=Asia/Shanghai 0 0 9 * * ?", shanghai, ""},
+		{"TZ=UTC @hourly", time.UTC, ""},
+		{"0 0 9 * * ?", nil, ""},
+		{"TZ=Asia/Shanghai", nil, "Missing spec after time zone"},
+		{"CRON_TZ=Nowhere/Void 0 0 9 * * ?", nil, "Failed to load time zone"},
+	}
+
+	for _, c := range entries {
+		actual, err := Parse(c.expr)
+		if len(c.err) != 0 && (err == nil || !strings.Contains(err.Error(), c.err)) {
+			t.Errorf("%s => expected %v, got %v", c.expr, c.err, err)
+		}
+		if len(c.err) == 0 && err != nil {
+			t.Errorf("%s => unexpected error %v", c.expr, err)
+		}
+		if err == nil && !sameLocation(actual.(*SpecSchedule).Location, c.location) {
+			t.Errorf("%s => expected location %v, got %v", c.expr, c.location, actual.(*SpecSchedule).Location)
+		}
+	}
+}
+
+func sameLocation(a, b *time.Location) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.String() == b.String()
+}
+
+func TestParseHash(t *testing.T) {
+	entries := []struct {
+		expr  string
+		check func(s *SpecSchedule) bool
+	}{
+		{"0 H * * * ?", func(s *SpecSchedule) bool { return bits.OnesCount64(s.Minute) == 1 }},
+		{"0 H(0-29) * * * ?", func(s *SpecSchedule) bool {
+			return bits.OnesCount64(s.Minute) == 1 && s.Minute&getBits(0, 29, 1) > 0
+		}},
+		{"0 H/15 * * * ?", func(s *SpecSchedule) bool {
+			return bits.OnesCount64(s.Minute) == 4 && bits.TrailingZeros64(s.Minute) < 15
+		}},
+		{"0 H(10-39)/10 * * * ?", func(s *SpecSchedule) bool {
+			first := uint(bits.TrailingZeros64(s.Minute))
+			return bits.OnesCount64(s.Minute) == 3 && first >= 10 && first < 20 && s.Minute == getBits(first, 39, 10)
+		}},
+		{"0 0 0 H * ?", func(s *SpecSchedule) bool {
+			return bits.OnesCount64(s.Dom) == 1 && s.Dom&getBits(1, 28, 1) > 0
+		}},
+		{"0 0 0 ? * H(MON-FRI)", func(s *SpecSchedule) bool {
+			return bits.OnesCount64(s.Dow) == 1 && s.Dow&getBits(1, 5, 1) > 0
+		}},
+		{"0 5,H 0 * * ?", func(s *SpecSchedule) bool { return s.Minute&(1<<5) > 0 && bits.OnesCount64(s.Minute) <= 2 }},
+	}
+
+	for _, c := range entries {
+		for _, name := range []string{"report", "backup", "cleanup", ""} {
+			sched, err := ParseWithName(c.expr, name)
+			if err != nil {
+				t.Errorf("%s (%s) => unexpected error %v", c.expr, name, err)
+				continue
+			}
+			if !c.check(sched.(*SpecSchedule)) {
+				t.Errorf("%s (%s) => unexpected schedule %+v", c.expr, name, sched)
+			}
+			again, _ := ParseWithName(c.expr, name)
+			if !reflect.DeepEqual(sched, again) {
+				t.Errorf("%s (%s) => not stable: %+v != %+v", c.expr, name, sched, again)
+			}
+		}
+	}
+}
+
+func TestParseHashSpreads(t *testing.T) {
+	minutes := make(map[uint64]bool)
+	for i := 0; i < 100; i++ {
+		sched, err := ParseWithName("0 H * * * ?", fmt.Sprintf("job-%d", i))
+		if err != nil {
+			t.Fatal(err)
+		}
+		minutes[sched.(*SpecSchedule).Minute] = true
+	}
+	if len(minutes) < 20 {
+		t.Errorf("expected names to spread over the hour, got %d distinct minutes", len(minutes))
+	}
+
+	// Fields are hashed independently.
+	same := 0
+	for i := 0; i < 100; i++ {
+		sched, _ := ParseWithName("H H * * * ?", fmt.Sprintf("job-%d", i))
+		if s := sched.(*SpecSchedule); s.Second == s.Minute {
+			same++
+		}
+	}
+	if same > 10 {
+		t.Errorf("expected seconds and minutes to be hashed independently, %d of 100 are equal", same)
+	}
+}
+
+func TestParseHashErrors(t *testing.T) {
+	var tests = []struct {
+		expr, err string
+	}{
+		{"0 H(30-10) * * * ?", "beyond end of range"},
+		{"0 H(0-60) * * * ?", "above maximum"},
+		{"0 H(0) * * * ?", "must be H(a-b)"},
+		{"0 H/0 * * * ?", "should be a positive number"},
+		{"0 H/5/5 * * * ?", "Too many slashes"},
+		{"0 HX * * * ?", "Failed to parse hash token"},
+	}
+	for _, c := range tests {
+		_, err := ParseWithName(c.expr, "report")
+		if err == nil || !strings.Contains(err.Error(), c.err) {
+			t.Errorf("%s => expected %v, got %v", c.expr, c.err, err)
+		}
+	}
+}
+
+func TestParseWrapAround(t *testing.T) {
+	entries := []struct {
+		expr     string
+		expected *SpecSchedule
+	}{
+		{"0 0 22-2 * * ?", &SpecSchedule{
+			Second: 1,
+			Minute: 1,
+			Hour:   1<<22 | 1<<23 | 1<<0 | 1<<1 | 1<<2,
+			Dom:    all(dom),
+			Month:  all(months),
+			Dow:    all(dow),
+		}},
+		{"0 0 0 ? NOV-FEB FRI-MON", &SpecSchedule{
+			Second: 1,
+			Minute: 1,
+			Hour:   1,
+			Dom:    all(dom),
+			Month:  1<<11 | 1<<12 | 1<<1 | 1<<2,
+			Dow:    1<<5 | 1<<6 | 1<<0 | 1<<1,
+		}},
+		{"0 0 0 ? * SAT-MON/2", &SpecSchedule{
+			Second: 1,
+			Minute: 1,
+			Hour:   1,
+			Dom:    all(dom),
+			Month:  all(months),
+			Dow:    1<<6 | 1<<1,
+		}},
+	}
+
+	for _, c := range entries {
+		actual, err := Parse(c.expr)
+		if err != nil {
+			t.Errorf("%s => unexpected error %v", c.expr, err)
+			continue
+		}
+		if !reflect.DeepEqual(actual, c.expected) {
+			t.Errorf("%s => expected %+v, got %+v", c.expr, c.expected, actual)
+		}
+	}
+}
+
+func TestParseError(t *testing.T) {
+	const (
+		quartz   = Second | Minute | Hour | Dom | Month | DowOptional | DayModifiers | YearOptional
+		standard = Minute | Hour | Dom | Month | Dow | Descriptor
+		full     = Second | Minute | Hour | Dom | Month | DowOptional | Descriptor
+	)
+	entries := []struct {
+		options ParseOption
+		expr    string
+		kind    ParseErrorKind
+		field   string
+		index   int
+		offset  int
+		token   string
+	}{
+		{full, "", ParseErrorEmpty, "", -1, 0, ""},
+		{full, "0 0 * *", ParseErrorFieldCount, "", -1, 0, "0 0 * *"},
+		{full, "0 x * * * ?", ParseErrorNumber, "minute", 1, 2, "x"},
+		{full, "0 0 25 * * ?", ParseErrorRange, "hour", 2, 4, "25"},
+		{full, "0 0 0 * FOO ?", ParseErrorName, "month", 4, 8, "FOO"},
+		{full, "0 */0 * * * ?", ParseErrorStep, "minute", 1, 2, "*/0"},
+		{full, "0 5-7-9 * * * ?", ParseErrorSyntax, "minute", 1, 2, "5-7-9"},
+		{full, "0  0 0 1 13", ParseErrorRange, "month", 4, 9, "13"},
+		{full, "0 H(0) * * * ?", ParseErrorSyntax, "minute", 1, 2, "H(0)"},
+		{full, "CRON_TZ=Asia/Shanghai 0 x * * * ?", ParseErrorNumber, "minute", 1, 24, "x"},
+		{full, "TZ=UTC   @fortnightly", ParseErrorDescriptor, "", -1, 9, "@fortnightly"},
+		{full, "CRON_TZ=Nowhere/Void 0 * * * * ?", ParseErrorTimeZone, "", -1, 8, "Nowhere/Void"},
+		{full, "CRON_TZ=UTC ", ParseErrorTimeZone, "", -1, 0, ""},
+		{full, "@every 5x", ParseErrorDescriptor, "", -1, 0, "@every 5x"},
+		{standard, "0 0 1-40 * *", ParseErrorRange, "day of month", 2, 4, "1-40"},
+		{standard, "0 0 * * MON,x", ParseErrorName, "day of week", 4, 12, "x"},
+		{quartz, "0 0 0 ? * FRI#9", ParseErrorModifier, "day of week", 5, 10, "FRI#9"},
+		{quartz, "0 0 0 40W * ?", ParseErrorModifier, "day of month", 3, 6, "40W"},
+		{quartz, "0 0 0 * * ? 2100", ParseErrorRange, "year", 6, 12, "2100"},
+	}
+
+	for _, c := range entries {
+		_, err := NewParser(c.options).Parse(c.expr)
+		var perr *ParseError
+		if !errors.As(err, &perr) {
+			t.Errorf("%q => expected a ParseError, got %v", c.expr, err)
+			continue
+		}
+		if perr.Spec != c.expr || perr.Kind != c.kind || perr.Field != c.field || perr.Index != c.index ||
+			perr.Offset != c.offset || perr.Token != c.token {
+			t.Errorf("%q => expected %v %q #%d at %d %q, got %v %q #%d at %d %q", c.expr,
+				c.kind, c.field, c.index, c.offset, c.token,
+				perr.Kind, perr.Field, perr.Index, perr.Offset, perr.Token)
+		}
+		if c.field != "" && !strings.Contains(err.Error(), c.field) {
+			t.Errorf("%q => expected the field in %q", c.expr, err)
+		}
+	}
+
+	// The underlying error is kept.
+	_, err := Parse("0 x * * * ?")
+	if !errors.Is(err, strconv.ErrSyntax) {
+		t.Errorf("expected strconv.ErrSyntax, got %v", err)
+	}
+
+	// Errors of the Cron methods are ParseErrors too.
+	var perr *ParseError
+	if err := New().AddFunc("0 0 99 * * ?", func() {}); !errors.As(err, &perr) || perr.Field != "hour" {
+		t.Errorf("expected a ParseError for the hour field, got %v", err)
+	}
+}
@@ -0,0 +1,199 @@
+package cron
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Saturation decides what happens to a run when the Pool has no free worker.
+type Saturation int
+
+const (
+	// SaturationRunLate keeps the run waiting until a worker is free, so it
+	// runs late rather than not at all.
+	SaturationRunLate Saturation = iota
+	// SaturationWait keeps the run waiting at most Pool.MaxWait, then drops it.
+	SaturationWait
+	// SaturationDrop drops the run immediately.
+	SaturationDrop
+)
+
+// Pool bounds the number of jobs a Cron runs at once, overall and per group
+// (see JobEntry.Group). Set it on Cron.Pool before starting the Cron.
+type Pool struct {
+	// Max bounds the number of runs overall. Zero means no limit.
+	Max int
+
+	// Groups bounds the number of runs per group name. Groups without a
+	// limit are only bound by Max.
+	Groups map[string]int
+
+	// Saturation decides what happens to a run when the pool is full.
+	Saturation Saturation
+
+	// MaxWait is how long a run waits with SaturationWait.
+	MaxWait time.Duration
+
+	mux     sync.Mutex
+	slots   chan struct{}
+	groups  map[string]chan struct{}
+	waiting int
+	queued  int
+	dropped int
+}
+
+// PoolStats holds the counters of a Pool.
+type PoolStats struct {
+	// Waiting is the number of runs waiting for a worker now.
+	Waiting int
+	// Queued is the number of runs which had to wait for a worker.
+	Queued int
+	// Dropped is the number of runs dropped because the pool was full.
+	Dropped int
+}
+
+// NewPool returns a Pool running at most max jobs at once.
+func NewPool(max int) *Pool {
+	return &Pool{Max: max}
+}
+
+// Stats returns a snapshot of the pool counters.
+func (p *Pool) Stats() PoolStats {
+	p.mux.Lock()
+	defer p.mux.Unlock()
+	return PoolStats{
+		Waiting: p.waiting,
+		Queued:  p.queued,
+		Dropped: p.dropped,
+	}
+}
+
+// acquire takes a worker for a run of the given group, waiting on clock. It
+// returns false if the run is dropped, either by the saturation policy or
+// because ctx is done. A nil Pool never limits.
+func (p *Pool) acquire(ctx context.Context, group string, clock Clock) bool {
+	if p == nil {
+		return true
+	}
+	groupSlots, slots := p.channels(group)
+
+	// Try without waiting first, so only saturated runs are counted.
+	if p.tryAcquire(groupSlots, slots) {
+		return true
+	}
+
+	p.mux.Lock()
+	if p.Saturation == SaturationDrop {
+		p.dropped++
+		p.mux.Unlock()
+		return false
+	}
+	p.waiting++
+	p.queued++
+	p.mux.Unlock()
+
+	var timeout <-chan time.Time
+	if p.Saturation == SaturationWait {
+		timer := clock.NewTimer(p.MaxWait)
+		defer timer.Stop()
+		timeout = timer.C()
+	}
+
+	ok := p.waitAcquire(ctx, timeout, groupSlots, slots)
+
+	p.mux.Lock()
+	p.waiting--
+	if !ok {
+		p.dropped++
+	}
+	p.mux.Unlock()
+	return ok
+}
+
+// release returns the worker taken by acquire.
+func (p *Pool) release(group string) {
+	if p == nil {
+		return
+	}
+	groupSlots, slots := p.channels(group)
+	if slots != nil {
+		<-slots
+	}
+	if groupSlots != nil {
+		<-groupSlots
+	}
+}
+
+// channels returns the semaphores of the group and of the whole pool, nil if
+// unlimited.
+func (p *Pool) channels(group string) (chan struct{}, chan struct{}) {
+	p.mux.Lock()
+	defer p.mux.Unlock()
+
+	if p.slots == nil && p.Max > 0 {
+		p.slots = make(chan struct{}, p.Max)
+	}
+	max, ok := p.Groups[group]
+	if !ok || max <= 0 {
+		return nil, p.slots
+	}
+	if p.groups == nil {
+		p.groups = make(map[string]chan struct{})
+	}
+	if p.groups[group] == nil {
+		p.groups[group] = make(chan struct{}, max)
+	}
+	return p.groups[group], p.slots
+}
+
+func (p *Pool) tryAcquire(groupSlots, slots chan struct{}) bool {
+	if groupSlots != nil {
+		select {
+		case groupSlots <- struct{}{}:
+		default:
+			return false
+		}
+	}
+	if slots != nil {
+		select {
+		case slots <- struct{}{}:
+		default:
+			if groupSlots != nil {
+				<-groupSlots
+			}
+			return false
+		}
+	}
+	return true
+}
+
+// waitAcquire takes the group worker, then the pool worker, so that a run
+// waiting on its group does not hold a worker of the whole pool.
+func (p *Pool) waitAcquire(ctx context.Context, timeout <-chan time.Time, groupSlots, slots chan struct{}) bool {
+	if groupSlots != nil {
+		select {
+		case groupSlots <- struct{}{}:
+		case <-timeout:
+			return false
+		case <-ctx.Done():
+			return false
+		}
+	}
+	if slots != nil {
+		select {
+		case slots <- struct{}{}:
+		case <-timeout:
+			if groupSlots != nil {
+				<-groupSlots
+			}
+			return false
+		case <-ctx.Done():
+			if groupSlots != nil {
+				<-groupSlots
+			}
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,147 @@
+package cron
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestPoolSaturation(t *testing.T) {
+	tests := []struct {
+		saturation Saturation
+		acquired   bool
+		stats      PoolStats
+	}{
+		{SaturationDrop, false, PoolStats{Dropped: 1}},
+		{SaturationWait, false, PoolStats{Queued: 1, Dropped: 1}},
+		{SaturationRunLate, true, PoolStats{Queued: 1}},
+	}
+
+	for _, test := range tests {
+		pool := &Pool{Max: 1, Saturation: test.saturation, MaxWait: 10 * time.Millisecond}
+		if !pool.acquire(context.Background(), "", WallClock) {
+			t.Fatalf("%d: expected first run acquires a worker", test.saturation)
+		}
+		go func() {
+			time.Sleep(50 * time.Millisecond)
+			pool.release("")
+		}()
+
+		if acquired := pool.acquire(context.Background(), "", WallClock); acquired != test.acquired {
+			t.Errorf("%d: (expected) acquired %v != %v (actual)", test.saturation, test.acquired, acquired)
+		}
+		if stats := pool.Stats(); stats != test.stats {
+			t.Errorf("%d: (expected) %+v != %+v (actual)", test.saturation, test.stats, stats)
+		}
+	}
+}
+
+// Test that a run waiting for a worker gives up once its context is done.
+func TestPoolCancel(t *testing.T) {
+	pool := NewPool(1)
+	pool.acquire(context.Background(), "", WallClock)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(10*time.Millisecond, cancel)
+	if pool.acquire(ctx, "", WallClock) {
+		t.Error("expected cancelled run is dropped")
+	}
+	if stats := pool.Stats(); stats.Waiting != 0 || stats.Dropped != 1 {
+		t.Errorf("unexpected stats %+v", stats)
+	}
+}
+
+// Test that jobs are limited by both the pool and their group.
+func TestPoolLimitsCron(t *testing.T) {
+	var (
+		mux     sync.Mutex
+		active  = map[string]int{}
+		peak    = map[string]int{}
+		release = make(chan struct{})
+	)
+	job := func(group string) Job2 {
+		return Job2Wrapper(func(ctx *JobContext) {
+			mux.Lock()
+			active[group]++
+			active[""]++
+			if active[group] > peak[group] {
+				peak[group] = active[group]
+			}
+			if active[""] > peak[""] {
+				peak[""] = active[""]
+			}
+			mux.Unlock()
+
+			<-release
+
+			mux.Lock()
+			active[group]--
+			active[""]--
+			mux.Unlock()
+		})
+	}
+
+	clock := NewFakeClock(getTime("Mon Jul 9 14:45 2012"))
+	cron := NewWithClock(time.UTC, clock)
+	cron.Pool = &Pool{Max: 3, Groups: map[string]int{"db": 1}}
+	for _, name := range []string{"db1", "db2", "db3"} {
+		cron.AddEntry(&JobEntry{Schedule: mustParse(t, "0 0 * * * ?"), Job: job("db"), Name: name, Group: "db"})
+	}
+	for _, name := range []string{"web1", "web2", "web3", "web4"} {
+		cron.AddEntry(&JobEntry{Schedule: mustParse(t, "0 0 * * * ?"), Job: job("web"), Name: name, Group: "web"})
+	}
+	cron.Start()
+	defer cron.Stop()
+
+	clock.BlockUntil(1)
+	clock.Set(getTime("Mon Jul 9 15:00 2012"))
+	// Three runs take the workers, the four others wait.
+	waitUntil(t, "the pool to fill", func() bool {
+		mux.Lock()
+		defer mux.Unlock()
+		return active[""] == 3 && cron.Pool.Stats().Waiting == 4
+	})
+	close(release)
+	waitUntil(t, "runs to finish", func() bool { return len(cron.Running()) == 0 })
+
+	mux.Lock()
+	defer mux.Unlock()
+	if peak["db"] != 1 {
+		t.Errorf("expected at most 1 db job at once, got %d", peak["db"])
+	}
+	if peak[""] != 3 {
+		t.Errorf("expected at most 3 jobs at once, got %d", peak[""])
+	}
+	if stats := cron.Pool.Stats(); stats.Queued != 4 || stats.Dropped != 0 {
+		t.Errorf("expected 4 queued runs, got %+v", stats)
+	}
+}
+
+// Test that SaturationWait gives up after MaxWait on the clock of the Cron.
+func TestPoolWaitFakeClock(t *testing.T) {
+	clock := NewFakeClock(getTime("Mon Jul 9 15:00 2012"))
+	pool := &Pool{Max: 1, Saturation: SaturationWait, MaxWait: time.Minute}
+	pool.acquire(context.Background(), "", clock)
+
+	acquired := make(chan bool)
+	go func() { acquired <- pool.acquire(context.Background(), "", clock) }()
+
+	clock.BlockUntil(1)
+	clock.Advance(59 * time.Second)
+	select {
+	case <-acquired:
+		t.Fatal("expected run still waiting before MaxWait")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	clock.Advance(time.Second)
+	select {
+	case ok := <-acquired:
+		if ok {
+			t.Error("expected run dropped after MaxWait")
+		}
+	case <-time.After(OneSecond):
+		t.Fatal("expected run to give up after MaxWait")
+	}
+}
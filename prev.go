@@ -0,0 +1,42 @@
+package cron
+
+import "time"
+
+// prev returns the latest activation of the schedule earlier than t and
+// later than limit, or the zero time if there is none. It looks for an
+// activation in ever larger windows before t, then narrows the window down
+// to the latest one by bisection. Next decides every activation, so they are
+// the same in both directions.
+func prev(schedule Schedule, t, limit time.Time) time.Time {
+	// t.Sub saturates, so span stays valid however far back limit is.
+	span := t.Sub(limit)
+	for window := time.Second; ; {
+		from := limit
+		if window < span {
+			from = t.Add(-window)
+		}
+		if next := schedule.Next(from); !next.IsZero() && next.Before(t) {
+			// There is an activation in (from, t). Find the latest time
+			// before it.
+			to := t
+			for to.Sub(from) > time.Nanosecond {
+				mid := from.Add(to.Sub(from) / 2)
+				if next := schedule.Next(mid); !next.IsZero() && next.Before(t) {
+					from = mid
+				} else {
+					to = mid
+				}
+			}
+			return schedule.Next(from)
+		}
+		if window >= span {
+			return time.Time{}
+		}
+		// Double the window without overflowing, up to the whole span.
+		if window > span-window {
+			window = span
+		} else {
+			window *= 2
+		}
+	}
+}
@@ -0,0 +1,36 @@
+package cron
+
+import (
+	"testing"
+	"time"
+)
+
+// Test that Prev returns when the first permitted year is further back than
+// a time.Duration can span.
+func TestPrevFarFromLimit(t *testing.T) {
+	parser := NewParser(Second | Minute | Hour | Dom | Month | DowOptional | YearOptional)
+	runs := []struct {
+		time, spec string
+		expected   string
+	}{
+		{"Mon Jan 1 00:00 2330", "0 0 0 1 1 * 2027", "Fri Jan 1 00:00 2027"},
+		{"Mon Jan 1 00:00 2330", "0 0 0 30 2 * 2027", ""},
+	}
+
+	for _, c := range runs {
+		sched, err := parser.Parse(c.spec)
+		if err != nil {
+			t.Fatal(err)
+		}
+		done := make(chan time.Time, 1)
+		go func() { done <- sched.(ReverseSchedule).Prev(getTime(c.time)) }()
+		select {
+		case actual := <-done:
+			if expected := getTime(c.expected); !actual.Equal(expected) {
+				t.Errorf("%s, \"%s\": (expected) %v != %v (actual)", c.time, c.spec, expected, actual)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("%s, \"%s\": Prev did not return", c.time, c.spec)
+		}
+	}
+}
@@ -0,0 +1,132 @@
+package cron
+
+import (
+	"context"
+	"math/rand"
+	"time"
+)
+
+// DefaultMaxDelay caps the wait between attempts when RetryPolicy.MaxDelay is
+// zero, unless Delay itself is longer.
+const DefaultMaxDelay = time.Hour
+
+// RetryPolicy describes how the scheduler retries a failed run: up to
+// MaxAttempts attempts, waiting Delay before the first retry and multiplying
+// the wait by Multiplier for each further one.
+type RetryPolicy struct {
+	// MaxAttempts is the total number of attempts, including the first.
+	MaxAttempts int
+
+	// Delay is the wait before the first retry.
+	Delay time.Duration
+
+	// Multiplier grows the wait between attempts. Values up to 1 keep a
+	// constant wait.
+	Multiplier float64
+
+	// MaxDelay caps the wait between attempts. Zero means DefaultMaxDelay,
+	// or Delay if longer.
+	MaxDelay time.Duration
+
+	// Jitter randomises each wait by up to this fraction of it, in [0, 1].
+	Jitter float64
+
+	// RetryIf reports whether err is worth retrying. Nil retries every error.
+	RetryIf func(err error) bool
+}
+
+// ConstantBackoff returns a RetryPolicy waiting delay between attempts.
+func ConstantBackoff(maxAttempts int, delay time.Duration) *RetryPolicy {
+	return &RetryPolicy{
+		MaxAttempts: maxAttempts,
+		Delay:       delay,
+	}
+}
+
+// ExponentialBackoff returns a RetryPolicy doubling the wait between attempts,
+// from delay up to maxDelay.
+func ExponentialBackoff(maxAttempts int, delay, maxDelay time.Duration) *RetryPolicy {
+	return &RetryPolicy{
+		MaxAttempts: maxAttempts,
+		Delay:       delay,
+		Multiplier:  2,
+		MaxDelay:    maxDelay,
+	}
+}
+
+// Backoff returns the wait before the given retry, attempt being the number
+// of the attempt which failed.
+func (p *RetryPolicy) Backoff(attempt int) time.Duration {
+	max := p.MaxDelay
+	if max <= 0 {
+		max = DefaultMaxDelay
+		if p.Delay > max {
+			max = p.Delay
+		}
+	}
+	delay := float64(p.Delay)
+	if p.Multiplier > 1 {
+		for i := 1; i < attempt && delay <= float64(max); i++ {
+			delay *= p.Multiplier
+		}
+	}
+	if delay > float64(max) {
+		delay = float64(max)
+	}
+	if p.Jitter > 0 {
+		delay += delay * p.Jitter * (2*rand.Float64() - 1)
+	}
+	return time.Duration(delay)
+}
+
+// retry reports whether the given failed attempt is retried, and after how
+// long. A nil RetryPolicy never retries.
+func (p *RetryPolicy) retry(attempt int, err error) (time.Duration, bool) {
+	if p == nil || attempt >= p.MaxAttempts {
+		return 0, false
+	}
+	if p.RetryIf != nil && !p.RetryIf(err) {
+		return 0, false
+	}
+	return p.Backoff(attempt), true
+}
+
+// attempt runs the job of entry once, retrying it according to its
+// RetryPolicy. Every attempt takes its own worker from the Pool. Retries stop
+// once Shutdown begins, leaving the error of the last attempt.
+func (c *Cron) attempt(entry *JobEntry, ctx context.Context, count int) {
+	drain := c.draining()
+	for attempt := 1; ; attempt++ {
+		if !c.Pool.acquire(ctx, entry.Group, c.clock) {
+			if ctx.Err() == nil {
+				c.logf("cron: dropped %s, worker pool is full", entry.Name)
+			}
+			return
+		}
+		outcome, err := c.invoke(entry, ctx, count, attempt)
+		c.Pool.release(entry.Group)
+		entry.state.record(outcome, err)
+		if err == nil {
+			return
+		}
+
+		delay, ok := entry.Retry.retry(attempt, err)
+		if !ok || ctx.Err() != nil || closed(drain) {
+			c.logf("cron: job %s failed (attempt %d): %v", entry.Name, attempt, err)
+			return
+		}
+		c.logf("cron: job %s failed, retrying in %s: %v", entry.Name, delay, err)
+
+		timer := c.clock.NewTimer(delay)
+		select {
+		case <-timer.C():
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-drain:
+			timer.Stop()
+			c.logf("cron: job %s failed (attempt %d), not retried during shutdown: %v", entry.Name, attempt, err)
+			return
+		}
+	}
+}
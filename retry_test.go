@@ -0,0 +1,146 @@
+package cron
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestRetryBackoff(t *testing.T) {
+	tests := []struct {
+		policy   *RetryPolicy
+		attempt  int
+		expected time.Duration
+	}{
+		{ConstantBackoff(3, time.Second), 1, time.Second},
+		{ConstantBackoff(3, time.Second), 2, time.Second},
+		{ExponentialBackoff(5, time.Second, 10*time.Second), 1, time.Second},
+		{ExponentialBackoff(5, time.Second, 10*time.Second), 2, 2 * time.Second},
+		{ExponentialBackoff(5, time.Second, 10*time.Second), 3, 4 * time.Second},
+		{ExponentialBackoff(5, time.Second, 10*time.Second), 5, 10 * time.Second},
+		{&RetryPolicy{Delay: time.Second, Multiplier: 2}, 100, DefaultMaxDelay},
+		{&RetryPolicy{Delay: time.Second, Multiplier: 2}, 1 << 30, DefaultMaxDelay},
+		{ConstantBackoff(3, 2*time.Hour), 2, 2 * time.Hour},
+		{&RetryPolicy{Delay: time.Second, Multiplier: 3}, 3, 9 * time.Second},
+	}
+
+	for _, test := range tests {
+		if actual := test.policy.Backoff(test.attempt); actual != test.expected {
+			t.Errorf("%+v, attempt %d: (expected) %s != %s (actual)",
+				test.policy, test.attempt, test.expected, actual)
+		}
+	}
+}
+
+func TestRetryJitter(t *testing.T) {
+	policy := &RetryPolicy{Delay: time.Second, Jitter: 0.5}
+	for i := 0; i < 100; i++ {
+		if actual := policy.Backoff(1); actual < 500*time.Millisecond || actual > 1500*time.Millisecond {
+			t.Fatalf("expected backoff within 50%% of 1s, got %s", actual)
+		}
+	}
+}
+
+func TestRetryIf(t *testing.T) {
+	permanent := errors.New("permanent")
+	policy := ConstantBackoff(3, time.Second)
+	policy.RetryIf = func(err error) bool { return err != permanent }
+
+	if _, ok := policy.retry(1, errors.New("transient")); !ok {
+		t.Error("expected transient error is retried")
+	}
+	if _, ok := policy.retry(1, permanent); ok {
+		t.Error("expected permanent error is not retried")
+	}
+	if _, ok := policy.retry(3, errors.New("transient")); ok {
+		t.Error("expected no retry after MaxAttempts")
+	}
+	if _, ok := (*RetryPolicy)(nil).retry(1, permanent); ok {
+		t.Error("expected nil policy never retries")
+	}
+}
+
+// Test that the scheduler retries a failing Job3 and exposes the attempt.
+func TestRetryJob(t *testing.T) {
+	var (
+		mux      sync.Mutex
+		attempts []int
+		done     = make(chan struct{})
+	)
+
+	cron := New()
+	cron.AddEntry(&JobEntry{
+		Schedule: Every(time.Second),
+		Name:     "retry",
+		Retry:    ConstantBackoff(3, 10*time.Millisecond),
+		Job: NewJob3To2(Job3Wrapper(func(ctx *JobContext) error {
+			mux.Lock()
+			defer mux.Unlock()
+			if ctx.Count > 1 {
+				return nil
+			}
+			attempts = append(attempts, ctx.Attempt)
+			if ctx.Attempt < 3 {
+				return errors.New("not yet")
+			}
+			close(done)
+			return nil
+		})),
+	})
+	cron.Start()
+	defer cron.Stop()
+
+	select {
+	case <-time.After(2 * OneSecond):
+		t.Fatal("expected job succeeds on third attempt")
+	case <-done:
+	}
+
+	mux.Lock()
+	defer mux.Unlock()
+	if len(attempts) != 3 || attempts[0] != 1 || attempts[2] != 3 {
+		t.Errorf("expected attempts [1 2 3], got %v", attempts)
+	}
+}
+
+// Test that Shutdown stops retrying a failing job, instead of waiting for the
+// remaining attempts.
+func TestRetryStopsOnShutdown(t *testing.T) {
+	var (
+		mux      sync.Mutex
+		attempts int
+	)
+	clock := NewFakeClock(getTime("Mon Jul 9 14:45 2012"))
+	cron := NewWithClock(time.UTC, clock)
+	cron.AddEntry(&JobEntry{
+		Schedule: mustParse(t, "0 0 * * * ?"),
+		Name:     "retry",
+		Retry:    ConstantBackoff(3, 300*time.Millisecond),
+		Job: NewJob3To2(Job3Wrapper(func(ctx *JobContext) error {
+			mux.Lock()
+			defer mux.Unlock()
+			attempts++
+			return errors.New("failed")
+		})),
+	})
+	cron.Start()
+
+	clock.BlockUntil(1)
+	clock.Set(getTime("Mon Jul 9 15:00 2012"))
+	// The scheduler and the backoff of the first attempt wait on the clock.
+	clock.BlockUntil(2)
+
+	if err := cron.Shutdown(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	mux.Lock()
+	defer mux.Unlock()
+	if attempts != 1 {
+		t.Errorf("expected 1 attempt, got %d", attempts)
+	}
+	if entries := cron.Entries(); entries[0].LastOutcome != OutcomeError {
+		t.Errorf("expected the last error kept, got %s", entries[0].LastOutcome)
+	}
+}
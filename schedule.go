@@ -13,3 +13,22 @@ type Schedule interface {
 	// NextTime is invoked initially, and then each time the job is run.
 	Next(time.Time) time.Time
 }
+
+// ReverseSchedule is implemented by schedules which can also look back in
+// time. SpecSchedule and ConstantDelaySchedule implement it.
+type ReverseSchedule interface {
+	Schedule
+
+	// Return the previous activation time, earlier than the given time.
+	Prev(time.Time) time.Time
+}
+
+// Prev returns the latest activation time of the schedule earlier than t, or
+// the zero time if there is none in the five years before t. Schedules which
+// do not implement ReverseSchedule are searched with their Next method.
+func Prev(schedule Schedule, t time.Time) time.Time {
+	if s, ok := schedule.(ReverseSchedule); ok {
+		return s.Prev(t)
+	}
+	return prev(schedule, t, t.AddDate(-5, 0, 0))
+}
@@ -6,6 +6,27 @@ import "time"
 // traditional crontab specification. It is computed initially and stored as bit sets.
 type SpecSchedule struct {
 	Second, Minute, Hour, Dom, Month, Dow uint64
+
+	// Day modifiers, see DayModifiers. LastDom has bit n set for "L-n",
+	// WeekdayDom bit n for "nW", LastDow bit d for "dL" and NthDow bit
+	// 8*d+n for "d#n".
+	LastDom, WeekdayDom, LastDow, NthDow uint64
+
+	// LastWeekdayDom is set for "LW".
+	LastWeekdayDom bool
+
+	// Year restricts the years of activation, see the Year parse option.
+	Year YearSet
+
+	// Location is the time zone the schedule is evaluated in, as given by a
+	// CRON_TZ= or TZ= prefix. Nil means the zone of the time passed to Next.
+	Location *time.Location
+
+	// Gap and Fold decide how activations around daylight saving time
+	// transitions are handled. The zero values skip activations in a gap and
+	// run twice in a fold.
+	Gap  GapPolicy
+	Fold FoldPolicy
 }
 
 // bounds provides a range of acceptable values (plus a map of name to value).
@@ -53,6 +74,34 @@ const (
 // NextTime returns the next time this schedule is activated, greater than the given
 // time.  If no time can be found to satisfy the schedule, return the zero time.
 func (s *SpecSchedule) Next(t time.Time) time.Time {
+	// Evaluate the schedule in its own time zone, if any, and convert the
+	// result back to the zone of the given time.
+	if s.Location != nil {
+		loc := t.Location()
+		next := s.nextDST(t.In(s.Location))
+		if next.IsZero() {
+			return next
+		}
+		return next.In(loc)
+	}
+	return s.nextDST(t)
+}
+
+// Prev returns the previous time this schedule was activated, earlier than
+// the given time, with the same time zone, daylight saving and year handling
+// as Next. If no time within five years, or in the years permitted, satisfies
+// the schedule, it returns the zero time.
+func (s *SpecSchedule) Prev(t time.Time) time.Time {
+	limit := t.AddDate(-5, 0, 0)
+	if !s.Year.IsZero() {
+		// Allow for the time zone of the schedule being ahead of t's.
+		limit = time.Date(s.Year.first(), time.January, 1, 0, 0, 0, 0, t.Location()).Add(-14 * time.Hour)
+	}
+	return prev(s, t, limit)
+}
+
+// next returns the next activation time in the time zone of t.
+func (s *SpecSchedule) next(t time.Time) time.Time {
 	// General approach:
 	// For Month, Day, Hour, Minute, Second:
 	// Check if the time value matches.  If yes, continue to the next field.
@@ -67,14 +116,28 @@ func (s *SpecSchedule) Next(t time.Time) time.Time {
 	// This flag indicates whether a field has been incremented.
 	added := false
 
-	// If no time is found within five years, return zero.
+	// If no time is found within five years, or after the last year
+	// permitted, return zero.
 	yearLimit := t.Year() + 5
+	if !s.Year.IsZero() {
+		yearLimit = s.Year.last()
+	}
 
 WRAP:
 	if t.Year() > yearLimit {
 		return time.Time{}
 	}
 
+	// Jump to the first permitted year.
+	if !s.Year.Contains(t.Year()) {
+		year := s.Year.next(t.Year())
+		if year == 0 {
+			return time.Time{}
+		}
+		added = true
+		t = time.Date(year, time.January, 1, 0, 0, 0, 0, t.Location())
+	}
+
 	// Find the first applicable month.
 	// If it's this month, then do nothing.
 	for 1<<uint(t.Month())&s.Month == 0 {
@@ -148,8 +211,8 @@ WRAP:
 // restrictions are satisfied by the given time.
 func dayMatches(s *SpecSchedule, t time.Time) bool {
 	var (
-		domMatch = 1<<uint(t.Day())&s.Dom > 0
-		dowMatch = 1<<uint(t.Weekday())&s.Dow > 0
+		domMatch = 1<<uint(t.Day())&s.Dom > 0 || domModifiersMatch(s, t)
+		dowMatch = 1<<uint(t.Weekday())&s.Dow > 0 || dowModifiersMatch(s, t)
 	)
 	if s.Dom&starBit > 0 || s.Dow&starBit > 0 {
 		return domMatch && dowMatch
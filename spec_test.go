@@ -247,3 +247,312 @@ func getTimeTZ(value string) time.Time {
 
 	return t
 }
+
+func TestNextDayModifiers(t *testing.T) {
+	parser := NewParser(Second | Minute | Hour | Dom | Month | DowOptional | DayModifiers)
+	runs := []struct {
+		time, spec string
+		expected   string
+	}{
+		// Last day of month, and days before it.
+		{"Mon Jul 9 23:35 2012", "0 0 0 L * ?", "Tue Jul 31 00:00 2012"},
+		{"Tue Jul 31 00:00 2012", "0 0 0 L * ?", "Fri Aug 31 00:00 2012"},
+		{"Mon Feb 1 00:00 2016", "0 0 0 L Feb ?", "Mon Feb 29 00:00 2016"},
+		{"Mon Jul 9 23:35 2012", "0 0 0 L-2 * ?", "Sun Jul 29 00:00 2012"},
+		{"Mon Jul 9 23:35 2012", "0 0 0 L-2,1 * ?", "Sun Jul 29 00:00 2012"},
+		{"Mon Jul 30 00:00 2012", "0 0 0 L-2,1 * ?", "Wed Aug 1 00:00 2012"},
+
+		// Nearest weekday: the 15th of Jul 2012 is a Sunday, of Sep a Saturday.
+		{"Mon Jul 9 23:35 2012", "0 0 0 15W * ?", "Mon Jul 16 00:00 2012"},
+		{"Mon Aug 20 00:00 2012", "0 0 0 15W * ?", "Fri Sep 14 00:00 2012"},
+		// The 1st of Sep 2012 is a Saturday, the weekday must stay in the month.
+		{"Sat Aug 25 00:00 2012", "0 0 0 1W * ?", "Mon Sep 3 00:00 2012"},
+		// The 31st of Mar 2012 is a Saturday.
+		{"Mon Mar 5 00:00 2012", "0 0 0 LW * ?", "Fri Mar 30 00:00 2012"},
+		{"Mon Jul 9 23:35 2012", "0 0 0 LW * ?", "Tue Jul 31 00:00 2012"},
+
+		// Last and nth day of week in month.
+		{"Mon Jul 9 23:35 2012", "0 0 0 ? * 5L", "Fri Jul 27 00:00 2012"},
+		{"Mon Jul 9 23:35 2012", "0 0 0 ? * FRIL", "Fri Jul 27 00:00 2012"},
+		{"Mon Jul 9 23:35 2012", "0 0 0 ? * FRI#2", "Fri Jul 13 00:00 2012"},
+		{"Sat Jul 14 00:00 2012", "0 0 0 ? * FRI#2", "Fri Aug 10 00:00 2012"},
+		{"Mon Jul 9 23:35 2012", "0 0 0 ? * MON#5", "Mon Jul 30 00:00 2012"},
+		{"Mon Jul 9 23:35 2012", "0 0 0 ? * 1#1,5L", "Fri Jul 27 00:00 2012"},
+		{"Mon Jul 9 23:35 2012", "0 0 0 ? * L", "Sat Jul 14 00:00 2012"},
+	}
+
+	for _, c := range runs {
+		sched, err := parser.Parse(c.spec)
+		if err != nil {
+			t.Error(err)
+			continue
+		}
+		actual := sched.Next(getTime(c.time))
+		expected := getTime(c.expected)
+		if !actual.Equal(expected) {
+			t.Errorf("%s, \"%s\": (expected) %v != %v (actual)", c.time, c.spec, expected, actual)
+		}
+	}
+}
+
+func TestNextYear(t *testing.T) {
+	parser := NewParser(Second | Minute | Hour | Dom | Month | Dow | YearOptional)
+	runs := []struct {
+		time, spec string
+		expected   string
+	}{
+		{"Mon Jul 9 23:35 2012", "0 0 0 * * ?", "Tue Jul 10 00:00 2012"},
+		{"Mon Jul 9 23:35 2012", "0 0 0 * * ? *", "Tue Jul 10 00:00 2012"},
+		{"Mon Jul 9 23:35 2012", "0 0 0 * * ? 2012", "Tue Jul 10 00:00 2012"},
+		{"Mon Dec 31 23:35 2012", "0 0 0 * * ? 2012", ""},
+
+		// Every Monday in 2027 only, far beyond five years.
+		{"Mon Jul 9 23:35 2012", "0 0 0 ? * MON 2027", "Mon Jan 4 00:00 2027"},
+		{"Mon Dec 27 00:00 2027", "0 0 0 ? * MON 2027", ""},
+
+		// Lists, ranges and steps.
+		{"Mon Jul 9 23:35 2012", "0 0 0 1 1 ? 2014,2016", "Wed Jan 1 00:00 2014"},
+		{"Wed Jan 1 00:00 2014", "0 0 0 1 1 ? 2014,2016", "Fri Jan 1 00:00 2016"},
+		{"Mon Jul 9 23:35 2012", "0 0 0 1 1 ? 2020-2030/5", "Wed Jan 1 00:00 2020"},
+		{"Wed Jan 1 00:00 2020", "0 0 0 1 1 ? 2020-2030/5", "Mon Jan 1 00:00 2025"},
+		{"Wed Jan 1 00:00 2020", "0 0 0 1 1 ? 2020/5", "Mon Jan 1 00:00 2025"},
+		{"Mon Jul 9 23:35 2012", "0 0 0 1 1 ? */10", "Wed Jan 1 00:00 2020"},
+
+		// Leap day in the permitted years only.
+		{"Mon Jul 9 23:35 2012", "0 0 0 29 Feb ? 2017-2019", ""},
+		{"Mon Jul 9 23:35 2012", "0 0 0 29 Feb ? 2017-2020", "Sat Feb 29 00:00 2020"},
+
+		// The last permitted year is over.
+		{"Mon Jul 9 23:35 2012", "0 0 0 * * ? 1999", ""},
+	}
+
+	for _, c := range runs {
+		sched, err := parser.Parse(c.spec)
+		if err != nil {
+			t.Error(err)
+			continue
+		}
+		actual := sched.Next(getTime(c.time))
+		expected := getTime(c.expected)
+		if !actual.Equal(expected) {
+			t.Errorf("%s, \"%s\": (expected) %v != %v (actual)", c.time, c.spec, expected, actual)
+		}
+	}
+}
+
+func TestNextWithSpecTz(t *testing.T) {
+	runs := []struct {
+		time, spec string
+		expected   string
+	}{
+		// 09:00 in Shanghai (+0800) is 01:00 UTC, or 21:00 in New York (-0400).
+		{"2016-07-03T00:00:00+0000", "CRON_TZ=Asia/Shanghai 0 0 9 * * ?", "2016-07-03T01:00:00+0000"},
+		{"2016-07-03T00:00:00-0400", "TZ=Asia/Shanghai 0 0 9 * * ?", "2016-07-03T21:00:00-0400"},
+		{"2016-07-03T00:00:00+0000", "CRON_TZ=Asia/Shanghai @daily", "2016-07-03T16:00:00+0000"},
+
+		// The time zone of the spec decides the day of week: Monday 01:00 in
+		// Shanghai is still Sunday in UTC.
+		{"2016-07-03T00:00:00+0000", "CRON_TZ=Asia/Shanghai 0 0 1 ? * MON", "2016-07-03T17:00:00+0000"},
+
+		// Daylight savings time of the spec zone applies.
+		{"2016-03-13T00:00:00+0000", "CRON_TZ=America/New_York 0 0 12 * * ?", "2016-03-13T16:00:00+0000"},
+		{"2016-03-12T00:00:00+0000", "CRON_TZ=America/New_York 0 0 12 * * ?", "2016-03-12T17:00:00+0000"},
+	}
+	for _, c := range runs {
+		sched, err := Parse(c.spec)
+		if err != nil {
+			t.Error(err)
+			continue
+		}
+		actual := sched.Next(getTimeTZ(c.time))
+		expected := getTimeTZ(c.expected)
+		if !actual.Equal(expected) {
+			t.Errorf("%s, \"%s\": (expected) %v != %v (actual)", c.time, c.spec, expected, actual)
+		}
+		if actual.Location() != getTimeTZ(c.time).Location() {
+			t.Errorf("%s, \"%s\": expected time in the zone of the given time, got %v", c.time, c.spec, actual)
+		}
+	}
+}
+
+func TestNextDST(t *testing.T) {
+	const fields = Second | Minute | Hour | Dom | Month | DowOptional | Descriptor
+	runs := []struct {
+		zone       string
+		options    ParseOption
+		time, spec string
+		expected   string
+	}{
+		// New York: 2am EST (-5) -> 3am EDT (-4)
+		{"America/New_York", 0, "2016-03-13T00:00:00-0500", "0 30 2 * * ?", "2016-03-14T02:30:00-0400"},
+		{"America/New_York", DSTNextValid, "2016-03-13T00:00:00-0500", "0 30 2 * * ?", "2016-03-13T03:00:00-0400"},
+		{"America/New_York", DSTNextValid, "2016-03-13T03:00:00-0400", "0 30 2 * * ?", "2016-03-14T02:30:00-0400"},
+		{"America/New_York", DSTNextValid, "2016-03-13T00:00:00-0500", "0 */20 2 * * ?", "2016-03-13T03:00:00-0400"},
+		{"America/New_York", DSTNextValid, "2016-03-13T03:00:00-0400", "0 */20 2 * * ?", "2016-03-14T02:00:00-0400"},
+		{"America/New_York", DSTNextValid, "2016-03-13T00:00:00-0500", "0 0 3 * * ?", "2016-03-13T03:00:00-0400"},
+		{"America/New_York", DSTNextValid, "2016-03-13T00:00:00-0500", "0 30 1 * * ?", "2016-03-13T01:30:00-0500"},
+
+		// New York: 2am EDT (-4) -> 1am EST (-5)
+		{"America/New_York", 0, "2016-11-06T00:00:00-0400", "0 30 1 * * ?", "2016-11-06T01:30:00-0400"},
+		{"America/New_York", 0, "2016-11-06T01:30:00-0400", "0 30 1 * * ?", "2016-11-06T01:30:00-0500"},
+		{"America/New_York", DSTFirstOccurrence, "2016-11-06T00:00:00-0400", "0 30 1 * * ?", "2016-11-06T01:30:00-0400"},
+		{"America/New_York", DSTFirstOccurrence, "2016-11-06T01:30:00-0400", "0 30 1 * * ?", "2016-11-07T01:30:00-0500"},
+		{"America/New_York", DSTSecondOccurrence, "2016-11-06T00:00:00-0400", "0 30 1 * * ?", "2016-11-06T01:30:00-0500"},
+		{"America/New_York", DSTSecondOccurrence, "2016-11-06T01:45:00-0400", "0 30 1 * * ?", "2016-11-06T01:30:00-0500"},
+		{"America/New_York", DSTSecondOccurrence, "2016-11-06T01:30:00-0500", "0 30 1 * * ?", "2016-11-07T01:30:00-0500"},
+		{"America/New_York", DSTFirstOccurrence, "2016-11-06T00:00:00-0400", "0 0 2 * * ?", "2016-11-06T02:00:00-0500"},
+
+		// London: 1am GMT (+0) -> 2am BST (+1), 2am BST (+1) -> 1am GMT (+0)
+		{"Europe/London", 0, "2016-03-27T00:00:00+0000", "0 30 1 * * ?", "2016-03-28T01:30:00+0100"},
+		{"Europe/London", DSTNextValid, "2016-03-27T00:00:00+0000", "0 30 1 * * ?", "2016-03-27T02:00:00+0100"},
+		{"Europe/London", DSTFirstOccurrence, "2016-10-30T01:30:00+0100", "0 30 1 * * ?", "2016-10-31T01:30:00+0000"},
+		{"Europe/London", DSTSecondOccurrence, "2016-10-30T00:00:00+0100", "0 30 1 * * ?", "2016-10-30T01:30:00+0000"},
+
+		// Sydney: 2am AEST (+10) -> 3am AEDT (+11), 3am AEDT (+11) -> 2am AEST (+10)
+		{"Australia/Sydney", DSTNextValid, "2016-10-02T00:00:00+1000", "0 30 2 * * ?", "2016-10-02T03:00:00+1100"},
+		{"Australia/Sydney", DSTFirstOccurrence, "2016-04-03T00:00:00+1100", "0 30 2 * * ?", "2016-04-03T02:30:00+1100"},
+		{"Australia/Sydney", DSTSecondOccurrence, "2016-04-03T00:00:00+1100", "0 30 2 * * ?", "2016-04-03T02:30:00+1000"},
+
+		// Lord Howe Island shifts by half an hour.
+		{"Australia/Lord_Howe", 0, "2016-10-02T00:00:00+1030", "0 15 2 * * ?", "2016-10-03T02:15:00+1100"},
+		{"Australia/Lord_Howe", DSTNextValid, "2016-10-02T00:00:00+1030", "0 15 2 * * ?", "2016-10-02T02:30:00+1100"},
+		{"Australia/Lord_Howe", DSTFirstOccurrence, "2016-04-03T01:45:00+1100", "0 45 1 * * ?", "2016-04-04T01:45:00+1030"},
+		{"Australia/Lord_Howe", DSTSecondOccurrence, "2016-04-03T00:00:00+1100", "0 45 1 * * ?", "2016-04-03T01:45:00+1030"},
+
+		// The policies apply to the zone of the spec.
+		{"UTC", DSTNextValid, "2016-03-13T00:00:00+0000", "CRON_TZ=America/New_York 0 30 2 * * ?", "2016-03-13T07:00:00+0000"},
+		{"UTC", DSTFirstOccurrence, "2016-11-06T05:30:00+0000", "TZ=America/New_York 0 30 1 * * ?", "2016-11-07T06:30:00+0000"},
+		{"UTC", DSTSecondOccurrence, "2016-11-06T00:00:00+0000", "CRON_TZ=America/New_York @daily", "2016-11-06T04:00:00+0000"},
+	}
+
+	for _, c := range runs {
+		loc, err := time.LoadLocation(c.zone)
+		if err != nil {
+			t.Fatal(err)
+		}
+		sched, err := NewParser(fields | c.options).Parse(c.spec)
+		if err != nil {
+			t.Error(err)
+			continue
+		}
+		actual := sched.Next(getTimeTZ(c.time).In(loc))
+		expected := getTimeTZ(c.expected)
+		if !actual.Equal(expected) {
+			t.Errorf("%s %s, \"%s\": (expected) %v != %v (actual)", c.zone, c.time, c.spec, expected, actual)
+		}
+	}
+}
+
+func TestNextWrapAround(t *testing.T) {
+	runs := []struct {
+		time, spec string
+		expected   string
+	}{
+		// Overnight hours
+		{"Mon Jul 9 21:30 2012", "0 0 22-2 * * ?", "Mon Jul 9 22:00 2012"},
+		{"Mon Jul 9 23:30 2012", "0 0 22-2 * * ?", "Tue Jul 10 00:00 2012"},
+		{"Tue Jul 10 02:30 2012", "0 0 22-2 * * ?", "Tue Jul 10 22:00 2012"},
+
+		// Weekends, and across the end of the year
+		{"Tue Jul 10 12:00 2012", "0 0 0 ? * FRI-MON", "Fri Jul 13 00:00 2012"},
+		{"Sun Jul 15 12:00 2012", "0 0 0 ? * FRI-MON", "Mon Jul 16 00:00 2012"},
+		{"Mon Jul 16 12:00 2012", "0 0 0 ? * FRI-MON", "Fri Jul 20 00:00 2012"},
+		{"Sat Mar 3 12:00 2012", "0 0 0 1 NOV-FEB ?", "Thu Nov 1 00:00 2012"},
+		{"Mon Dec 3 12:00 2012", "0 0 0 1 NOV-FEB ?", "Tue Jan 1 00:00 2013"},
+	}
+
+	for _, c := range runs {
+		sched, err := Parse(c.spec)
+		if err != nil {
+			t.Error(err)
+			continue
+		}
+		actual := sched.Next(getTime(c.time))
+		expected := getTime(c.expected)
+		if !actual.Equal(expected) {
+			t.Errorf("%s, \"%s\": (expected) %v != %v (actual)", c.time, c.spec, expected, actual)
+		}
+	}
+}
+
+func TestPrev(t *testing.T) {
+	parser := NewParser(Second | Minute | Hour | Dom | Month | DowOptional | Descriptor | DayModifiers | YearOptional |
+		DSTNextValid | DSTSecondOccurrence)
+	runs := []struct {
+		time, spec string
+		expected   string
+	}{
+		// Simple cases
+		{"Mon Jul 9 14:45 2012", "0 0/15 * * * *", "Mon Jul 9 14:30 2012"},
+		{"Mon Jul 9 14:45:00.001 2012", "0 0/15 * * * *", "Mon Jul 9 14:45 2012"},
+		{"Mon Jul 9 14:59 2012", "0 0/15 * * * *", "Mon Jul 9 14:45 2012"},
+		{"Mon Jul 9 14:59:59 2012", "* * * * * ?", "Mon Jul 9 14:59:58 2012"},
+
+		// Wrap around hours, days, months and years
+		{"Mon Jul 9 15:10 2012", "0 20-35/15 * * * *", "Mon Jul 9 14:35 2012"},
+		{"Mon Jul 9 00:10 2012", "0 20-35/15 * * * *", "Sun Jul 8 23:35 2012"},
+		{"Sun Jul 1 00:10 2012", "0 20-35/15 * * * *", "Sat Jun 30 23:35 2012"},
+		{"Tue Jan 1 00:00 2013", "0 0 12 * * ?", "Mon Dec 31 12:00 2012"},
+
+		// Days of week and month, and modifiers
+		{"Mon Jul 9 14:45 2012", "0 0 0 ? * FRI", "Fri Jul 6 00:00 2012"},
+		{"Mon Jul 9 14:45 2012", "0 0 0 29 2 ?", "Wed Feb 29 00:00 2012"},
+		{"Mon Jul 9 14:45 2012", "0 0 0 L * ?", "Sat Jun 30 00:00 2012"},
+		{"Mon Jul 9 14:45 2012", "0 0 0 ? * FRI#2", "Fri Jun 8 00:00 2012"},
+
+		// Years
+		{"Mon Jul 9 14:45 2012", "0 0 0 1 1 ? 2001", "Mon Jan 1 00:00 2001"},
+		{"Mon Jul 9 14:45 2012", "0 0 0 1 1 ? 2013", ""},
+		{"Mon Jul 9 14:45 2012", "0 0 0 30 2 ?", ""},
+
+		// Time zone of the spec
+		{"2016-07-03T00:00:00+0000", "CRON_TZ=Asia/Shanghai 0 0 9 * * ?", "2016-07-02T01:00:00+0000"},
+
+		// Daylight saving: 02:30 is skipped and runs at 03:00, 01:30 runs
+		// after clocks are turned back.
+		{"2016-03-13T04:00:00-0400", "0 30 2 * * ?", "2016-03-13T03:00:00-0400"},
+		{"2016-03-13T03:00:00-0400", "0 30 2 * * ?", "2016-03-12T02:30:00-0500"},
+		{"2016-11-06T01:45:00-0500", "0 30 1 * * ?", "2016-11-06T01:30:00-0500"},
+		{"2016-11-06T01:20:00-0500", "0 30 1 * * ?", "2016-11-05T01:30:00-0400"},
+	}
+
+	for _, c := range runs {
+		sched, err := parser.Parse(c.spec)
+		if err != nil {
+			t.Error(err)
+			continue
+		}
+		actual := sched.(ReverseSchedule).Prev(getTime(c.time))
+		expected := getTime(c.expected)
+		if !actual.Equal(expected) {
+			t.Errorf("%s, \"%s\": (expected) %v != %v (actual)", c.time, c.spec, expected, actual)
+		}
+		if actual.IsZero() {
+			continue
+		}
+		if next := sched.Next(actual); !next.IsZero() && next.Before(getTime(c.time)) {
+			t.Errorf("%s, \"%s\": Next(%v) = %v is between Prev and the given time", c.time, c.spec, actual, next)
+		}
+	}
+}
+
+// Test that Prev finds the activations of a schedule not implementing
+// ReverseSchedule through its Next method.
+func TestPrevWithNext(t *testing.T) {
+	sched, _ := Parse("0 0/15 * * * *")
+	custom := struct{ Schedule }{sched}
+	if _, ok := Schedule(custom).(ReverseSchedule); ok {
+		t.Fatal("expected a schedule without Prev")
+	}
+	for _, c := range []struct{ time, expected string }{
+		{"Mon Jul 9 14:45 2012", "Mon Jul 9 14:30 2012"},
+		{"Mon Jul 9 00:00 2012", "Sun Jul 8 23:45 2012"},
+	} {
+		if actual := Prev(custom, getTime(c.time)); !actual.Equal(getTime(c.expected)) {
+			t.Errorf("%s: (expected) %v != %v (actual)", c.time, getTime(c.expected), actual)
+		}
+	}
+	if actual := Prev(struct{ Schedule }{&ZeroSchedule{}}, getTime("Mon Jul 9 14:45 2012")); !actual.IsZero() {
+		t.Errorf("expected no activation, got %v", actual)
+	}
+}
@@ -0,0 +1,145 @@
+package cron
+
+import (
+	"errors"
+	"sort"
+	"sync"
+	"time"
+)
+
+// EntryState is the part of a JobEntry kept by a Store across restarts.
+type EntryState struct {
+	Name     string
+	PrevTime time.Time
+	NextTime time.Time
+	Count    int
+
+	// Done marks an entry retired by the scheduler, such as a once job which
+	// has run or an entry past its NotAfter. It is not scheduled again when
+	// re-added under the same name.
+	Done bool
+}
+
+// ErrNameRequired is returned when adding an entry without a name by spec to
+// a Cron with a Store.
+var ErrNameRequired = errors.New("cron: entries need a name when a Store is set")
+
+// Store persists the state of the entries of a Cron, keyed by entry name.
+// The Cron loads it when started and re-associates the states with the
+// entries added under the same names. It saves the state of an entry when
+// it is added or run, and deletes it when the entry is removed.
+type Store interface {
+	// Load returns the states of all the entries stored.
+	Load() ([]EntryState, error)
+
+	// Save stores the state of an entry, replacing any with the same name.
+	Save(state EntryState) error
+
+	// Delete removes the state of the named entry.
+	Delete(name string) error
+}
+
+// MemoryStore is a Store keeping states in memory, for tests.
+type MemoryStore struct {
+	mux    sync.Mutex
+	states map[string]EntryState
+}
+
+// NewMemoryStore returns an empty MemoryStore.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{states: make(map[string]EntryState)}
+}
+
+// Load returns the states stored, sorted by name.
+func (s *MemoryStore) Load() ([]EntryState, error) {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	return sortedStates(s.states), nil
+}
+
+// Save stores the state of an entry.
+func (s *MemoryStore) Save(state EntryState) error {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	s.states[state.Name] = state
+	return nil
+}
+
+// Delete removes the state of the named entry.
+func (s *MemoryStore) Delete(name string) error {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	delete(s.states, name)
+	return nil
+}
+
+func sortedStates(states map[string]EntryState) []EntryState {
+	list := make([]EntryState, 0, len(states))
+	for _, state := range states {
+		list = append(list, state)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Name < list[j].Name })
+	return list
+}
+
+// entryState returns the persistent part of the entry.
+func (e *JobEntry) entryState() EntryState {
+	return EntryState{
+		Name:     e.Name,
+		PrevTime: e.PrevTime,
+		NextTime: e.NextTime,
+		Count:    e.Count,
+		Done:     e.done,
+	}
+}
+
+// load reads the Store when the scheduler starts.
+func (c *Cron) load() {
+	c.stored = nil
+	if c.Store == nil {
+		return
+	}
+	states, err := c.Store.Load()
+	if err != nil {
+		c.logf("cron: failed to load store: %v", err)
+		return
+	}
+	c.stored = make(map[string]EntryState, len(states))
+	for _, state := range states {
+		c.stored[state.Name] = state
+	}
+}
+
+// restore applies the stored state to the entry. It returns false if the
+// entry was retired and must not be scheduled.
+func (c *Cron) restore(e *JobEntry) bool {
+	state, ok := c.stored[e.Name]
+	if !ok || e.generated {
+		return true
+	}
+	e.PrevTime = state.PrevTime
+	e.Count = state.Count
+	e.done = state.Done
+	return !e.done
+}
+
+// save writes the state of the entry to the Store.
+func (c *Cron) save(e *JobEntry) {
+	if c.Store == nil || e.generated {
+		return
+	}
+	if err := c.Store.Save(e.entryState()); err != nil {
+		c.logf("cron: failed to save %s: %v", e.Name, err)
+	}
+}
+
+// forget deletes the state of the named entry from the Store.
+func (c *Cron) forget(name string) {
+	if c.Store == nil {
+		return
+	}
+	delete(c.stored, name)
+	if err := c.Store.Delete(name); err != nil {
+		c.logf("cron: failed to delete %s: %v", name, err)
+	}
+}
@@ -0,0 +1,211 @@
+package cron
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFileStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cron.log")
+	store, err := NewFileStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	prev := getTime("Mon Jul 9 14:00 2012")
+	for i := 1; i <= 50; i++ {
+		store.Save(EntryState{Name: "hourly", PrevTime: prev, Count: i})
+	}
+	store.Save(EntryState{Name: "once", Count: 1, Done: true})
+	store.Save(EntryState{Name: "removed", Count: 3})
+	store.Delete("removed")
+	store.Close()
+
+	// Simulate a crash in the middle of appending a record.
+	file, _ := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0644)
+	file.WriteString(`{"state":{"Name":"torn`)
+	file.Close()
+
+	store, err = NewFileStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+
+	states, _ := store.Load()
+	expected := []EntryState{
+		{Name: "hourly", PrevTime: prev, Count: 50},
+		{Name: "once", Count: 1, Done: true},
+	}
+	if len(states) != len(expected) {
+		t.Fatalf("(expected) %v != %v (actual)", expected, states)
+	}
+	for i := range expected {
+		if !states[i].PrevTime.Equal(expected[i].PrevTime) {
+			t.Errorf("(expected) %v != %v (actual)", expected[i].PrevTime, states[i].PrevTime)
+		}
+		states[i].PrevTime = expected[i].PrevTime
+	}
+	if !reflect.DeepEqual(states, expected) {
+		t.Errorf("(expected) %v != %v (actual)", expected, states)
+	}
+}
+
+// Test that a corrupt record followed by others is reported, instead of
+// silently losing the states it held.
+func TestFileStoreCorrupt(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cron.log")
+	log := `{"state":{"Name":"hourly","Count":1}}
+{"state":{"Name":"hou
+{"state":{"Name":"daily","Count":1}}
+`
+	if err := os.WriteFile(path, []byte(log), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewFileStore(path); err == nil || !strings.Contains(err.Error(), "line 2") {
+		t.Errorf("expected an error for line 2, got %v", err)
+	}
+}
+
+// Test that the state of entries survives a restart of the cron, and that a
+// once job which ran is not run again.
+func TestCronStore(t *testing.T) {
+	store := NewMemoryStore()
+	clock := NewFakeClock(getTime("Mon Jul 9 14:45 2012"))
+	runs := make(chan string, 10)
+
+	start := func() *Cron {
+		cron := NewWithClock(time.UTC, clock)
+		cron.Store = store
+		cron.AddFunc2("0 0 * * * ?", func(ctx *JobContext) { runs <- ctx.Name }, "hourly")
+		cron.AddOnceFunc2("0 0 * * * ?", func(ctx *JobContext) { runs <- ctx.Name }, "once")
+		cron.Start()
+		clock.BlockUntil(1)
+		return cron
+	}
+
+	cron := start()
+	clock.Set(getTime("Mon Jul 9 15:00 2012"))
+	clock.BlockUntil(1)
+	cron.Stop()
+	for i := 0; i < 2; i++ {
+		<-runs
+	}
+
+	cron = start()
+	defer cron.Stop()
+	entries := cron.Entries()
+	if len(entries) != 1 || entries[0].Name != "hourly" {
+		t.Fatalf("expected only hourly entry after restart, got %v", entries)
+	}
+	if entries[0].Count != 1 || !entries[0].PrevTime.Equal(getTime("Mon Jul 9 15:00 2012")) {
+		t.Errorf("expected restored count 1 and prev time, got %d, %v", entries[0].Count, entries[0].PrevTime)
+	}
+
+	clock.Set(getTime("Mon Jul 9 16:00 2012"))
+	if name := <-runs; name != "hourly" {
+		t.Errorf("expected hourly job runs, got %s", name)
+	}
+	clock.BlockUntil(1)
+	if state, _ := store.Load(); state[0].Name != "hourly" || state[0].Count != 2 || !state[1].Done {
+		t.Errorf("unexpected stored states %v", state)
+	}
+}
+
+// Test that MaxRuns holds across restarts, including for the runs fired to
+// catch up with missed ones.
+func TestCronStoreMaxRuns(t *testing.T) {
+	store := NewMemoryStore()
+	clock := NewFakeClock(getTime("Mon Jul 9 14:45 2012"))
+	runs := make(chan int, 10)
+
+	start := func() *Cron {
+		cron := NewWithClock(time.UTC, clock)
+		cron.Store = store
+		cron.AddEntry(&JobEntry{
+			Schedule: mustParse(t, "0 0 * * * ?"),
+			Job:      Job2Wrapper(func(ctx *JobContext) { runs <- ctx.Count }),
+			Name:     "limited",
+			MaxRuns:  3,
+			Misfire:  MisfireFireAll,
+		})
+		cron.Start()
+		clock.BlockUntil(1)
+		return cron
+	}
+
+	cron := start()
+	clock.Set(getTime("Mon Jul 9 15:00 2012"))
+	if count := <-runs; count != 1 {
+		t.Errorf("expected run 1, got %d", count)
+	}
+	clock.BlockUntil(1)
+	cron.Stop()
+
+	// Three runs were missed, but only two are left.
+	clock.Set(getTime("Mon Jul 9 18:30 2012"))
+	cron = start()
+	defer cron.Stop()
+	// The catch-up runs start concurrently, in any order.
+	if first, second := <-runs, <-runs; first+second != 5 || first*second != 6 {
+		t.Errorf("expected runs 2 and 3, got %d and %d", first, second)
+	}
+	select {
+	case count := <-runs:
+		t.Errorf("expected no more runs, got %d", count)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	entries := cron.Entries()
+	if len(entries) != 1 || !entries[0].Completed || entries[0].Count != 3 {
+		t.Errorf("expected completed entry after 3 runs, got %+v", entries[0])
+	}
+	if state, _ := store.Load(); !state[0].Done || state[0].Count != 3 {
+		t.Errorf("unexpected stored state %v", state)
+	}
+}
+
+// Test that entries need a name to be stored, since generated names change on
+// every start.
+func TestCronStoreNames(t *testing.T) {
+	store := NewMemoryStore()
+	clock := NewFakeClock(getTime("Mon Jul 9 14:45 2012"))
+	cron := NewWithClock(time.UTC, clock)
+	cron.Store = store
+
+	if err := cron.AddFunc("0 0 * * * ?", func() {}); err != ErrNameRequired {
+		t.Errorf("expected ErrNameRequired, got %v", err)
+	}
+	if err := cron.AddOnceFunc2("0 0 * * * ?", func(*JobContext) {}); err != ErrNameRequired {
+		t.Errorf("expected ErrNameRequired, got %v", err)
+	}
+	if err := cron.AddFunc("0 0 * * * ?", func() {}, "named"); err != nil {
+		t.Errorf("unexpected error %v", err)
+	}
+	cron.Schedule2(mustParse(t, "0 0 * * * ?"), Job2Wrapper(func(*JobContext) {}))
+	cron.Start()
+	defer cron.Stop()
+
+	if entries := cron.Entries(); len(entries) != 2 {
+		t.Fatalf("expected 2 entries, got %v", entries)
+	}
+	if states, _ := store.Load(); len(states) != 1 || states[0].Name != "named" {
+		t.Errorf("expected only the named entry stored, got %v", states)
+	}
+}
+
+// Test that generated names are unique, even within the same second.
+func TestGeneratedNames(t *testing.T) {
+	cron := New()
+	cron.AddFunc("0 0 * * * ?", func() {})
+	cron.AddFunc("0 0 * * * ?", func() {})
+	cron.Schedule(mustParse(t, "0 0 * * * ?"), JobWrapper(func() {}))
+	entries := cron.Entries()
+	if len(entries) != 3 || entries[0].Name == entries[1].Name || entries[1].Name == entries[2].Name {
+		t.Errorf("expected distinct generated names, got %v, %v, %v", entries[0].Name, entries[1].Name, entries[2].Name)
+	}
+}
@@ -0,0 +1,87 @@
+package cron
+
+import (
+	"math/bits"
+	"strings"
+)
+
+// The bounds of the year field, as in Quartz.
+var years = bounds{1970, 2099, nil}
+
+// YearSet is a bit set of the years a SpecSchedule may activate in, from 1970
+// to 2099. The zero value places no restriction on the year.
+type YearSet [3]uint64
+
+// IsZero reports whether the set places no restriction on the year.
+func (y YearSet) IsZero() bool {
+	return y == YearSet{}
+}
+
+// Contains reports whether the set permits the given year.
+func (y YearSet) Contains(year int) bool {
+	if y.IsZero() {
+		return true
+	}
+	if year < int(years.min) || year > int(years.max) {
+		return false
+	}
+	i := uint(year) - years.min
+	return y[i/64]&(1<<(i%64)) > 0
+}
+
+// next returns the first year permitted from the given one, included, or 0
+// if there is none.
+func (y YearSet) next(year int) int {
+	if year < int(years.min) {
+		year = int(years.min)
+	}
+	for ; year <= int(years.max); year++ {
+		if y.Contains(year) {
+			return year
+		}
+	}
+	return 0
+}
+
+// first returns the first year permitted, or 0 if there is none.
+func (y YearSet) first() int {
+	return y.next(int(years.min))
+}
+
+// last returns the last year permitted, or 0 if there is none.
+func (y YearSet) last() int {
+	for i := len(y) - 1; i >= 0; i-- {
+		if y[i] != 0 {
+			return int(years.min) + i*64 + 63 - bits.LeadingZeros64(y[i])
+		}
+	}
+	return 0
+}
+
+func (y *YearSet) set(year uint) {
+	i := year - years.min
+	y[i/64] |= 1 << (i % 64)
+}
+
+// getYearField returns the set of years the field represents, or error
+// parsing field value. A star alone leaves the set empty, placing no
+// restriction on the year.
+func getYearField(field string) (YearSet, error) {
+	var set YearSet
+	for _, expr := range strings.FieldsFunc(field, func(r rune) bool { return r == ',' }) {
+		start, end, step, star, err := parseRange(expr, years)
+		if err != nil {
+			return YearSet{}, err
+		}
+		if star && step == 1 {
+			return YearSet{}, nil
+		}
+		if start > end {
+			return YearSet{}, parseErrorf(ParseErrorRange, expr, "Beginning of range (%d) beyond end of range (%d): %s", start, end, expr)
+		}
+		for year := start; year <= end; year += step {
+			set.set(year)
+		}
+	}
+	return set, nil
+}